@@ -0,0 +1,235 @@
+package mocjson
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Canonicalize reads a JSON value from r and returns it in the JSON
+// Canonicalization Scheme (RFC 8785), so equal values give equal bytes for
+// hashing and signing: the members of objects are sorted by the UTF-16 code
+// units of the keys, numbers are formatted as IEEE 754 doubles like
+// ECMAScript does, strings are escaped minimally, and there are no white
+// spaces.
+//
+// Duplicate keys and numbers out of the range of doubles are errors.
+func Canonicalize(r io.Reader) ([]byte, error) {
+	pa := NewParser(r)
+
+	b, err := pa.appendCanonical(nil)
+	if err != nil {
+		return nil, fmt.Errorf("canonicalize error: %w", err)
+	}
+
+	if !pa.lx.ExpectEOF() {
+		if err := pa.lx.sc.Err(); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("scanner error: %w", err)
+		}
+		return nil, errors.New("expect EOF")
+	}
+
+	return b, nil
+}
+
+// appendCanonical parses the next value and appends it to b in the canonical
+// form.
+func (pa *Parser) appendCanonical(b []byte) ([]byte, error) {
+	switch pa.lx.NextTokenType() {
+	case TokenTypeBeginObject:
+		return pa.appendCanonicalObject(b)
+
+	case TokenTypeBeginArray:
+		return pa.appendCanonicalArray(b)
+
+	case TokenTypeNull:
+		if !pa.lx.ExpectNull() {
+			return nil, errors.New("expect null")
+		}
+		return append(b, "null"...), nil
+
+	case TokenTypeBool:
+		v, ok := pa.lx.ExpectBool()
+		if !ok {
+			return nil, errors.New("expect bool")
+		}
+		return strconv.AppendBool(b, v), nil
+
+	case TokenTypeNumber:
+		v, ok := pa.lx.ExpectNumberBytes()
+		if !ok {
+			return nil, errors.New("expect number")
+		}
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse float64 error: %w", err)
+		}
+		if f == 0 {
+			// -0 is 0 in ECMAScript.
+			f = 0
+		}
+		return appendFloat(b, f, 64), nil
+
+	case TokenTypeString:
+		v, ok := pa.lx.ExpectString()
+		if !ok {
+			return nil, errors.New("expect string")
+		}
+		return appendString(b, v), nil
+	}
+
+	return nil, errors.New("invalid token type")
+}
+
+func (pa *Parser) appendCanonicalArray(b []byte) ([]byte, error) {
+	pa.lx.sc.Skip(1)
+	b = append(b, '[')
+
+	// empty array
+	if pa.lx.NextTokenType() == TokenTypeEndArray {
+		pa.lx.sc.Skip(1)
+		return append(b, ']'), nil
+	}
+
+	for i := 0; ; i++ {
+		var err error
+		if b, err = pa.appendCanonical(b); err != nil {
+			return nil, fmt.Errorf("canonicalize [%d] error: %w", i, err)
+		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndArray:
+			pa.lx.sc.Skip(1)
+			return append(b, ']'), nil
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			b = append(b, ',')
+
+		default:
+			return nil, errors.New("expect value separator or end array")
+		}
+	}
+}
+
+func (pa *Parser) appendCanonicalObject(b []byte) ([]byte, error) {
+	pa.lx.sc.Skip(1)
+
+	type member struct {
+		k string
+		v []byte
+	}
+
+	var members []member
+
+	// empty object
+	if pa.lx.NextTokenType() == TokenTypeEndObject {
+		pa.lx.sc.Skip(1)
+		return append(b, "{}"...), nil
+	}
+
+	for {
+		k, err := pa.ParseString()
+		if err != nil {
+			return nil, fmt.Errorf("parse key error: %w", err)
+		}
+
+		if !pa.lx.ExpectNameSeparator() {
+			return nil, errors.New("expect name separator")
+		}
+
+		v, err := pa.appendCanonical(nil)
+		if err != nil {
+			return nil, fmt.Errorf("canonicalize %s error: %w", k, err)
+		}
+		members = append(members, member{k: k, v: v})
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndObject:
+			pa.lx.sc.Skip(1)
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			continue
+
+		default:
+			return nil, errors.New("expect value separator or end object")
+		}
+
+		break
+	}
+
+	slices.SortFunc(members, func(a, b member) int {
+		return compareUTF16(a.k, b.k)
+	})
+
+	b = append(b, '{')
+	for i, m := range members {
+		if i > 0 {
+			if m.k == members[i-1].k {
+				return nil, fmt.Errorf("duplicate key %q", m.k)
+			}
+			b = append(b, ',')
+		}
+		b = appendString(b, m.k)
+		b = append(b, ':')
+		b = append(b, m.v...)
+	}
+
+	return append(b, '}'), nil
+}
+
+// compareUTF16 compares a and b by their UTF-16 code units.
+func compareUTF16(a, b string) int {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			return cmp.Compare(utf16Order(ra), utf16Order(rb))
+		}
+		a, b = a[na:], b[nb:]
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+// utf16Order maps r to the order of its first UTF-16 code unit. The runes
+// beyond the BMP are encoded with the surrogates, which come before
+// U+E000-U+FFFF, so the latter are moved after all of them.
+func utf16Order(r rune) rune {
+	if r >= 0xe000 && r <= 0xffff {
+		return r + utf8.MaxRune
+	}
+
+	return r
+}
+
+// encodeCanonical encodes v in the canonical form. v is encoded as usual, and
+// the output is parsed again, so that the fields of structs and the output
+// of Marshalers are canonicalized as well.
+func (en *Encoder) encodeCanonical(v any) error {
+	var buf bytes.Buffer
+
+	sub := NewEncoder(&buf)
+	if err := sub.EncodeValue(v); err != nil {
+		return err
+	}
+	if err := sub.Flush(); err != nil {
+		return err
+	}
+
+	pa := NewParser(&buf)
+
+	b, err := pa.appendCanonical(en.buf)
+	if err != nil {
+		return fmt.Errorf("canonicalize error: %w", err)
+	}
+	en.buf = b
+
+	return en.write()
+}
@@ -0,0 +1,203 @@
+package mocjson
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       string
+		want    string
+		wantErr bool
+	}{
+		{
+			// RFC 8785 Section 3.2.2
+			name: "rfc example",
+			b: `{
+				"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+				"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+				"literals": [null, true, false]
+			}`,
+			want: `{"literals":[null,true,false],` +
+				`"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],` +
+				`"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// RFC 8785 Section 3.2.3
+			name: "sorting",
+			b: `{
+				"\u20ac": "Euro Sign",
+				"\r": "Carriage Return",
+				"\ufb33": "Hebrew Letter Dalet With Dagesh",
+				"1": "One",
+				"\ud83d\ude00": "Emoji: Grinning Face",
+				"\u0080": "Control",
+				"\u00f6": "Latin Small Letter O With Diaeresis"
+			}`,
+			want: `{"\r":"Carriage Return","1":"One","` + "\u0080" + `":"Control",` +
+				`"ö":"Latin Small Letter O With Diaeresis","€":"Euro Sign",` +
+				`"😀":"Emoji: Grinning Face","דּ":"Hebrew Letter Dalet With Dagesh"}`,
+		},
+		{
+			name: "nested",
+			b:    ` { "b" : [ { "d" : 1 , "c" : -0 } , [ ] , { } ] , "a" : "" } `,
+			want: `{"a":"","b":[{"c":0,"d":1},[],{}]}`,
+		},
+		{
+			name: "prefix keys",
+			b:    `{"ab": 1, "a": 2, "": 3}`,
+			want: `{"":3,"a":2,"ab":1}`,
+		},
+		{
+			name: "scalar",
+			b:    `1.0e0`,
+			want: `1`,
+		},
+		{
+			name:    "duplicate key",
+			b:       `{"a": 1, "b": 2, "a": 3}`,
+			wantErr: true,
+		},
+		{
+			name:    "number out of range",
+			b:       `[1e400]`,
+			wantErr: true,
+		},
+		{
+			name:    "trailing value",
+			b:       `{} {}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			b:       `{"a" 1}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Canonicalize(strings.NewReader(tt.b))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalize_Numbers(t *testing.T) {
+	t.Parallel()
+
+	// RFC 8785 Appendix B
+	tests := []struct {
+		bits uint64
+		want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+
+	for _, tt := range tests {
+		f := math.Float64frombits(tt.bits)
+		src := strconv.FormatFloat(f, 'g', -1, 64)
+
+		got, err := Canonicalize(strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%016x: got %s, want %s", tt.bits, got, tt.want)
+		}
+	}
+}
+
+type canonicalTestObject struct {
+	Zeta  string            `json:"zeta"`
+	Alpha float32           `json:"alpha"`
+	Map   map[string]int    `json:"map"`
+	Raw   RawValue          `json:"raw"`
+	Point testPoint         `json:"point"`
+	Big   int64             `json:"big"`
+	Inner map[string]string `json:"inner,omitempty"`
+}
+
+func TestEncoderOptions_Canonical(t *testing.T) {
+	t.Parallel()
+
+	v := canonicalTestObject{
+		Zeta:  "z",
+		Alpha: 0.5,
+		Map:   map[string]int{"דּ": 1, "\U0001f600": 2, "b": 3},
+		Raw:   RawValue(`{ "y": [ 1.50 ], "x": null }`),
+		Point: testPoint{X: -0.0, Y: 1e21},
+		Big:   math.MaxInt64,
+	}
+
+	var buf bytes.Buffer
+	en := NewEncoderWithOptions(&buf, EncoderOptions{Canonical: true})
+	if err := en.Encode(v); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"alpha":0.5,"big":9223372036854776000,"map":{"b":3,"😀":2,"דּ":1},` +
+		`"point":[0,1e+21],"raw":{"x":null,"y":[1.5]},"zeta":"z"}`
+	if got := buf.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// The result is a fixed point of Canonicalize.
+	got, err := Canonicalize(strings.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func BenchmarkCanonicalize(b *testing.B) {
+	s := "[" + strings.Repeat(`{"name":"ü😀","n":1.50,"tags":["x","y"],"id":"a","ok":true},`, 1000) +
+		"null]"
+	r := strings.NewReader(s)
+
+	for b.Loop() {
+		r.Reset(s)
+		if _, err := Canonicalize(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package mocjson
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const transcodeBufSize = 1024
+
+// transcodeReader detects the encoding of r on the first Read, and transcodes
+// it into UTF-8 without the BOM. Invalid code units are replaced with
+// U+FFFD.
+type transcodeReader struct {
+	r   io.Reader
+	err error
+
+	detected bool
+	// width is the size of the code units: 1 for UTF-8, 2 for UTF-16 and 4
+	// for UTF-32.
+	width int
+	order binary.ByteOrder
+
+	raw [transcodeBufSize]byte
+	// in holds the bytes not decoded yet, and out holds the decoded bytes
+	// not returned yet.
+	in  []byte
+	out []byte
+}
+
+func (tr *transcodeReader) Read(p []byte) (int, error) {
+	if !tr.detected {
+		tr.detect()
+	}
+
+	for len(tr.out) == 0 {
+		if tr.err != nil {
+			if len(tr.in) > 0 {
+				// truncated code unit
+				tr.out = utf8.AppendRune(tr.out[:0], utf8.RuneError)
+				tr.in = tr.in[:0]
+				break
+			}
+			return 0, tr.err
+		}
+
+		tr.fill()
+		tr.decode()
+	}
+
+	n := copy(p, tr.out)
+	tr.out = tr.out[n:]
+
+	return n, nil
+}
+
+func (tr *transcodeReader) fill() {
+	var n int
+	n, tr.err = tr.r.Read(tr.raw[:])
+	tr.in = append(tr.in, tr.raw[:n]...)
+}
+
+func (tr *transcodeReader) detect() {
+	tr.detected = true
+
+	for len(tr.in) < 4 && tr.err == nil {
+		tr.fill()
+	}
+
+	b := tr.in
+
+	var bom int
+	switch {
+	case bytes.HasPrefix(b, []byte{0x00, 0x00, 0xfe, 0xff}):
+		tr.width, tr.order, bom = 4, binary.BigEndian, 4
+	case bytes.HasPrefix(b, []byte{0xff, 0xfe, 0x00, 0x00}):
+		tr.width, tr.order, bom = 4, binary.LittleEndian, 4
+	case bytes.HasPrefix(b, []byte{0xfe, 0xff}):
+		tr.width, tr.order, bom = 2, binary.BigEndian, 2
+	case bytes.HasPrefix(b, []byte{0xff, 0xfe}):
+		tr.width, tr.order, bom = 2, binary.LittleEndian, 2
+	case bytes.HasPrefix(b, []byte(byteOrderMark)):
+		tr.width, bom = 1, len(byteOrderMark)
+
+	// A JSON text starts with an ASCII character, so the zero bytes around it
+	// tell the encoding.
+	case len(b) >= 4 && b[0] == 0 && b[1] == 0 && b[2] == 0:
+		tr.width, tr.order = 4, binary.BigEndian
+	case len(b) >= 4 && b[1] == 0 && b[2] == 0 && b[3] == 0:
+		tr.width, tr.order = 4, binary.LittleEndian
+	case len(b) >= 2 && b[0] == 0:
+		tr.width, tr.order = 2, binary.BigEndian
+	case len(b) >= 2 && b[1] == 0:
+		tr.width, tr.order = 2, binary.LittleEndian
+	default:
+		tr.width = 1
+	}
+
+	tr.in = tr.in[bom:]
+	tr.decode()
+}
+
+// decode moves the complete code units from in to out.
+func (tr *transcodeReader) decode() {
+	if tr.width == 1 {
+		tr.out = append(tr.out, tr.in...)
+		tr.in = tr.in[:0]
+		return
+	}
+
+	i := 0
+
+	for len(tr.in)-i >= tr.width {
+		var (
+			r rune
+			n = tr.width
+		)
+
+		if tr.width == 4 {
+			r = rune(tr.order.Uint32(tr.in[i:]))
+		} else {
+			r = rune(tr.order.Uint16(tr.in[i:]))
+
+			if utf16.IsSurrogate(r) && len(tr.in)-i >= 4 {
+				if r2 := utf16.DecodeRune(r, rune(tr.order.Uint16(tr.in[i+2:]))); r2 != utf8.RuneError {
+					r, n = r2, 4
+				}
+			} else if utf16.IsSurrogate(r) && tr.err == nil {
+				// The pair may be completed by the next read.
+				break
+			}
+		}
+
+		// AppendRune writes U+FFFD for the invalid ones.
+		tr.out = utf8.AppendRune(tr.out, r)
+		i += n
+	}
+
+	tr.in = append(tr.in[:0], tr.in[i:]...)
+}
@@ -0,0 +1,193 @@
+package mocjson
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+)
+
+// encodeText encodes s in UTF-16 or UTF-32 with the byte order.
+func encodeText(s string, width int, order binary.AppendByteOrder) []byte {
+	var b []byte
+
+	for _, r := range s {
+		if width == 4 {
+			b = order.AppendUint32(b, uint32(r))
+			continue
+		}
+		for _, u := range utf16.Encode([]rune{r}) {
+			b = order.AppendUint16(b, u)
+		}
+	}
+
+	return b
+}
+
+func TestParserOptions_DetectEncoding(t *testing.T) {
+	t.Parallel()
+
+	const doc = `{"a": ["ü", "😀", 1]}`
+	want := map[string]any{"a": []any{"ü", "😀", 1.0}}
+
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{"utf-8", []byte(doc)},
+		{"utf-8 bom", []byte(byteOrderMark + doc)},
+		{"utf-16be", encodeText(doc, 2, binary.BigEndian)},
+		{"utf-16le", encodeText(doc, 2, binary.LittleEndian)},
+		{"utf-16be bom", encodeText(byteOrderMark+doc, 2, binary.BigEndian)},
+		{"utf-16le bom", encodeText(byteOrderMark+doc, 2, binary.LittleEndian)},
+		{"utf-32be", encodeText(doc, 4, binary.BigEndian)},
+		{"utf-32le", encodeText(doc, 4, binary.LittleEndian)},
+		{"utf-32be bom", encodeText(byteOrderMark+doc, 4, binary.BigEndian)},
+		{"utf-32le bom", encodeText(byteOrderMark+doc, 4, binary.LittleEndian)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The one byte reads split the code units and the surrogate pairs.
+			for _, r := range []io.Reader{
+				bytes.NewReader(tt.b),
+				iotest.OneByteReader(bytes.NewReader(tt.b)),
+			} {
+				pa := NewParserWithOptions(r, ParserOptions{DetectEncoding: true})
+				got, err := pa.Parse()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("got %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTranscodeReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		b    []byte
+		want string
+	}{
+		{
+			name: "single character utf-16le",
+			b:    []byte{'1', 0},
+			want: "1",
+		},
+		{
+			name: "single character utf-32le",
+			b:    []byte{'1', 0, 0, 0},
+			want: "1",
+		},
+		{
+			name: "short utf-8",
+			b:    []byte("1"),
+			want: "1",
+		},
+		{
+			name: "truncated code unit",
+			b:    []byte{0, '"', 0, 'a', 0},
+			want: "\"a�",
+		},
+		{
+			name: "lone surrogates",
+			b:    []byte{0, '"', 0xd8, 0x3d, 0, 'a', 0xde, 0x00},
+			want: "\"�a�",
+		},
+		{
+			name: "truncated surrogate pair",
+			b:    []byte{0, '"', 0xd8, 0x3d},
+			want: "\"�",
+		},
+		{
+			name: "out of range utf-32",
+			b:    []byte{0, 0, 0, '"', 0, 0x11, 0, 0},
+			want: "\"�",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := io.ReadAll(&transcodeReader{r: iotest.OneByteReader(bytes.NewReader(tt.b))})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscodeReader_Error(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read error")
+	r := io.MultiReader(
+		bytes.NewReader(encodeText(`"ab`, 2, binary.BigEndian)),
+		iotest.ErrReader(errRead),
+	)
+
+	got, err := io.ReadAll(&transcodeReader{r: r})
+	if err != errRead {
+		t.Errorf("got %v, want %v", err, errRead)
+	}
+	if want := `"ab`; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParser_Parse_ByteOrderMark(t *testing.T) {
+	t.Parallel()
+
+	for _, r := range []io.Reader{
+		strings.NewReader(byteOrderMark + `[1]`),
+		iotest.OneByteReader(strings.NewReader(byteOrderMark + ` [1]`)),
+	} {
+		pa := NewParser(r)
+		got, err := pa.Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []any{1.0}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+
+	// Only the leading one is skipped.
+	pa := NewParser(strings.NewReader(`[1]` + byteOrderMark))
+	if _, err := pa.Parse(); err == nil {
+		t.Error("want an error for the trailing BOM")
+	}
+}
+
+func BenchmarkTranscodeReader(b *testing.B) {
+	bs := encodeText(
+		"["+strings.Repeat(`{"name": "ü😀", "n": 1},`, 1000)+"null]",
+		2,
+		binary.LittleEndian,
+	)
+	r := bytes.NewReader(bs)
+
+	for b.Loop() {
+		r.Reset(bs)
+		pa := NewParserWithOptions(r, ParserOptions{DetectEncoding: true})
+		if _, err := pa.Parse(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
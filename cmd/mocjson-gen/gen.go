@@ -0,0 +1,1013 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/high-moctane/mocjson-go"
+)
+
+const mocjsonPath = "github.com/high-moctane/mocjson-go"
+
+// maxFields is the maximum number of the fields of a struct, since the
+// generated parsers track the seen fields in a uint64 whose bit 0 is for the
+// unknown keys.
+const maxFields = 63
+
+type typeKind int
+
+const (
+	typeAny typeKind = iota
+	typeBool
+	typeInt64
+	typeFloat64
+	typeString
+	typePointer
+	typeSlice
+	typeStruct
+
+	// typeUnion is an interface type whose variants are registered with
+	// mocjson.RegisterVariant.
+	typeUnion
+
+	// typeDecode is a type which the generated code reads with Decode.
+	typeDecode
+)
+
+// goType is the Go type of an inferred shape, or of a field of a source file.
+type goType struct {
+	kind typeKind
+
+	// elem is the element type of a pointer or a slice.
+	elem *goType
+
+	// st is the struct type.
+	st *structType
+
+	// u is the union type.
+	u *unionType
+
+	// expr is the Go expression of a typeDecode type.
+	expr string
+}
+
+func (t *goType) String() string {
+	switch t.kind {
+	case typeBool:
+		return "bool"
+	case typeInt64:
+		return "int64"
+	case typeFloat64:
+		return "float64"
+	case typeString:
+		return "string"
+	case typePointer:
+		return "*" + t.elem.String()
+	case typeSlice:
+		return "[]" + t.elem.String()
+	case typeStruct:
+		return t.st.name
+	case typeUnion:
+		return t.u.name
+	case typeDecode:
+		return t.expr
+	}
+
+	return "any"
+}
+
+// validated reports whether a value of t may have the fields with validation
+// options, whose ValidationErrors need the paths.
+func (t *goType) validated() bool {
+	switch t.kind {
+	case typePointer, typeSlice:
+		return t.elem.validated()
+	case typeStruct, typeUnion, typeDecode:
+		return true
+	}
+
+	return false
+}
+
+// funcName is the name of t in the names of the helper functions.
+func (t *goType) funcName() string {
+	switch t.kind {
+	case typePointer:
+		return "Nullable" + t.elem.funcName()
+	case typeSlice:
+		return t.elem.funcName() + "Array"
+	case typeStruct:
+		return t.st.name
+	case typeUnion:
+		return t.u.name
+	}
+
+	s := t.String()
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+type structType struct {
+	name   string
+	fields []structField
+}
+
+// parseFunc returns the name of the parser function of st, which is
+// unexported if st is.
+func (st *structType) parseFunc() string {
+	return parseFunc(st.name)
+}
+
+// patternVar returns the name of the variable of the pattern option of f.
+func (st *structType) patternVar(f structField) string {
+	return strings.ToLower(st.name[:1]) + st.name[1:] + f.name + "Pattern"
+}
+
+type structField struct {
+	name     string
+	key      string
+	typ      *goType
+	optional bool
+
+	// aliases is set by the mocjson:"alias=<name>|..." option.
+	aliases []string
+	// nocase is set by the mocjson:"nocase" option.
+	nocase bool
+	// defaultValue is the Go literal of the mocjson:"default=<json>" option.
+	defaultValue string
+	// rules are the validation options, or nil.
+	rules *fieldRules
+
+	// discriminator means that the key is the discriminator of a union which
+	// the struct is a variant of, and that the value is skipped.
+	discriminator bool
+}
+
+// fieldRules are the validation options of a field, which the generated
+// parser checks in the same order and with the same ValidationErrors as Decode.
+type fieldRules struct {
+	// kind is the kind of the field, or of the value which it points to. It is
+	// reflect.Struct for a pointer to a type which is not a basic type, a slice
+	// or a map, which has only the nonempty option.
+	kind reflect.Kind
+	// pointer means that the field is a pointer, and that the rules except
+	// nonempty apply to the value which it points to.
+	pointer bool
+
+	min, max             float64
+	hasMin, hasMax       bool
+	minLength, maxLength int
+	hasMinLength         bool
+	hasMaxLength         bool
+	pattern              string
+	enum                 []string
+	nonempty             bool
+}
+
+// matches reports whether a field of st matches the key k as Decode looks it
+// up: by the key or an alias, or case-insensitively with the nocase option.
+func (st *structType) matches(k string) bool {
+	for _, f := range st.fields {
+		if f.key == k || slices.Contains(f.aliases, k) {
+			return true
+		}
+	}
+
+	for _, f := range st.fields {
+		if !f.nocase {
+			continue
+		}
+		if strings.EqualFold(f.key, k) ||
+			slices.ContainsFunc(f.aliases, func(a string) bool { return strings.EqualFold(a, k) }) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unionType is an interface type whose variants are registered with
+// mocjson.RegisterVariant.
+type unionType struct {
+	name     string
+	key      string
+	variants []unionVariant
+}
+
+type unionVariant struct {
+	values  []string
+	st      *structType
+	pointer bool
+}
+
+// parseFunc returns the name of the parser function of the type name.
+func parseFunc(name string) string {
+	if ast.IsExported(name) {
+		return "Parse" + name
+	}
+
+	return "parse" + strings.ToUpper(name[:1]) + name[1:]
+}
+
+type generator struct {
+	pkg string
+
+	// command is the mocjson-gen command which generates the code.
+	command string
+
+	structs []*structType
+	names   map[string]bool
+
+	// helpers are the types which need the helper functions, in the order of
+	// first use.
+	helpers []*goType
+	helped  map[string]bool
+
+	// imports are the standard packages which the generated code uses.
+	imports map[string]bool
+
+	// paths means that the generated code prepends the paths of the fields and
+	// the elements to the ValidationErrors as Decode does.
+	paths bool
+
+	// sourceImports are the imports of the source file which the generated
+	// code uses, with their names or "".
+	sourceImports map[string]string
+
+	buf bytes.Buffer
+}
+
+// generate generates the Go source which declares the type name for the
+// shape s and its parser.
+func generate(pkg, name string, s *shape) ([]byte, error) {
+	g := generator{
+		pkg:     pkg,
+		command: "infer",
+		names:   map[string]bool{name: true},
+		helped:  make(map[string]bool),
+		imports: map[string]bool{"errors": true},
+	}
+
+	var root *goType
+	switch s.kinds &^ kindNull {
+	case kindObject:
+		st, err := g.addStruct(s, name)
+		if err != nil {
+			return nil, err
+		}
+		root = &goType{kind: typeStruct, st: st}
+
+	case kindArray:
+		elem, err := g.typeOf(s.elem, elemName(name), name)
+		if err != nil {
+			return nil, err
+		}
+		root = &goType{kind: typeSlice, elem: elem}
+
+	default:
+		return nil, errors.New("the samples must be objects or arrays")
+	}
+
+	if root.kind == typeSlice {
+		g.printf("type %s %s\n\n", name, root)
+	}
+	for _, st := range g.structs {
+		g.writeStruct(st)
+	}
+
+	if root.kind == typeSlice {
+		g.printf("func Parse%s(pa *mocjson.Parser) (%s, error) {\n", name, name)
+		g.printf("return parse%s(pa)\n", g.helper(root))
+		g.printf("}\n\n")
+	}
+	for _, st := range g.structs {
+		g.writeStructParser(st)
+	}
+
+	return g.source()
+}
+
+// source writes the helpers and returns the formatted source.
+func (g *generator) source() ([]byte, error) {
+	// The helpers may add more helpers.
+	for i := 0; i < len(g.helpers); i++ {
+		g.writeHelper(g.helpers[i])
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by mocjson-gen %s. DO NOT EDIT.\n\n", g.command)
+	fmt.Fprintf(&out, "package %s\n\n", g.pkg)
+	imports := map[string]string{mocjsonPath: ""}
+	for path := range g.imports {
+		imports[path] = ""
+	}
+	maps.Copy(imports, g.sourceImports)
+
+	// The standard packages come first as goimports groups them.
+	paths := slices.SortedFunc(maps.Keys(imports), func(a, b string) int {
+		if isStdPackage(a) != isStdPackage(b) {
+			if isStdPackage(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	fmt.Fprintf(&out, "import (\n")
+	for i, path := range paths {
+		if i > 0 && isStdPackage(paths[i-1]) && !isStdPackage(path) {
+			fmt.Fprintf(&out, "\n")
+		}
+		if name := imports[path]; name != "" {
+			fmt.Fprintf(&out, "%s ", name)
+		}
+		fmt.Fprintf(&out, "%q\n", path)
+	}
+	fmt.Fprintf(&out, ")\n\n")
+	out.Write(g.buf.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+// isStdPackage reports whether the import path is of a standard package, whose
+// first element has no dot.
+func isStdPackage(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func (g *generator) printf(format string, a ...any) {
+	fmt.Fprintf(&g.buf, format, a...)
+}
+
+// typeOf returns the Go type of the shape s. The struct types are named name,
+// or parent+name if name is taken.
+func (g *generator) typeOf(s *shape, name, parent string) (*goType, error) {
+	var t *goType
+
+	switch s.kinds &^ kindNull {
+	case 0:
+		return &goType{kind: typeAny}, nil
+
+	case kindBool:
+		t = &goType{kind: typeBool}
+
+	case kindInt:
+		t = &goType{kind: typeInt64}
+
+	case kindFloat, kindInt | kindFloat:
+		t = &goType{kind: typeFloat64}
+
+	case kindString:
+		t = &goType{kind: typeString}
+
+	case kindArray:
+		// A slice is nil for null.
+		elem, err := g.typeOf(s.elem, elemName(name), parent)
+		if err != nil {
+			return nil, err
+		}
+		return &goType{kind: typeSlice, elem: elem}, nil
+
+	case kindObject:
+		st, err := g.addStruct(s, g.uniqueName(name, parent))
+		if err != nil {
+			return nil, err
+		}
+		t = &goType{kind: typeStruct, st: st}
+
+	default:
+		// The kinds cannot be unified.
+		return &goType{kind: typeAny}, nil
+	}
+
+	if s.kinds&kindNull != 0 {
+		t = &goType{kind: typePointer, elem: t}
+	}
+
+	return t, nil
+}
+
+func (g *generator) uniqueName(name, parent string) string {
+	if !g.names[name] {
+		g.names[name] = true
+		return name
+	}
+
+	base := parent + name
+	name = base
+	for i := 2; g.names[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	g.names[name] = true
+
+	return name
+}
+
+func (g *generator) addStruct(s *shape, name string) (*structType, error) {
+	if len(s.fields) > maxFields {
+		return nil, fmt.Errorf("type %s: too many fields", name)
+	}
+
+	st := &structType{name: name}
+	g.structs = append(g.structs, st)
+
+	fieldNames := make(map[string]bool)
+
+	for _, f := range s.fields {
+		fname := goName(f.key)
+		for i := 2; fieldNames[fname]; i++ {
+			fname = fmt.Sprintf("%s%d", goName(f.key), i)
+		}
+		fieldNames[fname] = true
+
+		t, err := g.typeOf(f.shape, fname, name)
+		if err != nil {
+			return nil, err
+		}
+
+		st.fields = append(st.fields, structField{
+			name:     fname,
+			key:      f.key,
+			typ:      t,
+			optional: s.optional(f),
+		})
+	}
+
+	return st, nil
+}
+
+func (g *generator) writeStruct(st *structType) {
+	g.printf("type %s struct {\n", st.name)
+	for _, f := range st.fields {
+		tag := f.key
+		if f.optional {
+			tag += ",omitempty"
+		}
+		g.printf("%s %s `json:%q`\n", f.name, f.typ, tag)
+	}
+	g.printf("}\n\n")
+}
+
+func (g *generator) writeStructParser(st *structType) {
+	// The aliases are in the table too, with the numbers of their fields.
+	var keys []string
+	var fields []int
+	for i, f := range st.fields {
+		for _, k := range append([]string{f.key}, f.aliases...) {
+			keys = append(keys, k)
+			fields = append(fields, i)
+		}
+	}
+
+	size := 1
+	for size < len(keys) {
+		size *= 2
+	}
+	seed, ok := mocjson.FindKeyHashSeed(keys, size)
+	for !ok {
+		size *= 2
+		seed, ok = mocjson.FindKeyHashSeed(keys, size)
+	}
+
+	slots := make(map[uint32]int)
+	for i, k := range keys {
+		slots[mocjson.KeyHash([]byte(k), seed)&uint32(size-1)] = i
+	}
+	sorted := slices.Sorted(maps.Keys(slots))
+
+	prefix := strings.ToLower(st.name[:1]) + st.name[1:]
+	zero := st.name + "{}"
+
+	g.printf("// %sKeys and %sFields are the perfect hash table of the keys of %s.\n",
+		prefix, prefix, st.name)
+	g.printf("// The fields are numbered from 1, and 0 means an unknown key.\n")
+	g.printf("const %sKeySeed = %d\n\n", prefix, seed)
+
+	g.printf("var %sKeys = [%d]string{\n", prefix, size)
+	for _, slot := range sorted {
+		g.printf("%d: %q,\n", slot, keys[slots[slot]])
+	}
+	g.printf("}\n\n")
+
+	g.printf("var %sFields = [%d]uint8{\n", prefix, size)
+	for _, slot := range sorted {
+		f := fields[slots[slot]]
+		g.printf("%d: %d, // %s\n", slot, f+1, commentKey(st.fields[f].key))
+	}
+	g.printf("}\n\n")
+
+	for _, f := range st.fields {
+		if f.rules != nil && f.rules.pattern != "" {
+			g.imports["regexp"] = true
+			g.printf("var %s = regexp.MustCompile(%q)\n\n", st.patternVar(f), f.rules.pattern)
+		}
+	}
+
+	g.printf("func %s(pa *mocjson.Parser) (%s, error) {\n", st.parseFunc(), st.name)
+	g.printf("lx := pa.Lexer()\n\n")
+	g.printf("if !lx.ExpectBeginObject() {\n")
+	g.printf("return %s, errors.New(\"expect begin object\")\n", zero)
+	g.printf("}\n\n")
+	g.printf("var ret %s\n", st.name)
+	g.printf("var seen uint64\n\n")
+	g.printf("if lx.NextTokenType() == mocjson.TokenTypeEndObject {\n")
+	g.printf("lx.ExpectEndObject()\n")
+	g.printf("goto Validate\n")
+	g.printf("}\n\n")
+
+	g.printf("for {\n")
+	g.printf("k, ok := lx.ExpectKeyBytes()\n")
+	g.printf("if !ok {\n")
+	g.printf("return %s, errors.New(\"expect string\")\n", zero)
+	g.printf("}\n\n")
+	g.printf("slot := mocjson.KeyHash(k, %sKeySeed) & %d\n", prefix, size-1)
+	g.printf("f := %sFields[slot]\n", prefix)
+	g.printf("if string(k) != %sKeys[slot] {\n", prefix)
+	g.printf("f = 0\n")
+	g.printf("}\n")
+	for i, f := range st.fields {
+		if !f.nocase {
+			continue
+		}
+		g.imports["bytes"] = true
+
+		var conds []string
+		for _, k := range append([]string{f.key}, f.aliases...) {
+			conds = append(conds, fmt.Sprintf("bytes.EqualFold(k, []byte(%q))", k))
+		}
+		if len(conds) == 1 {
+			g.printf("if f == 0 && %s {\n", conds[0])
+		} else {
+			g.printf("if f == 0 && (%s) {\n", strings.Join(conds, " ||\n"))
+		}
+		g.printf("f = %d\n", i+1)
+		g.printf("}\n")
+	}
+	g.printf("if seen&(1<<f) != 0 {\n")
+	g.printf("return %s, errors.New(\"duplicate key\")\n", zero)
+	g.printf("}\n")
+	g.printf("seen |= 1 << f\n\n")
+	g.printf("if !lx.ExpectNameSeparator() {\n")
+	g.printf("return %s, errors.New(\"expect name separator\")\n", zero)
+	g.printf("}\n\n")
+
+	g.printf("switch f {\n")
+	for i, f := range st.fields {
+		g.printf("case %d: // %s\n", i+1, commentKey(f.key))
+		if f.discriminator {
+			g.imports["fmt"] = true
+			g.printf("// discriminator\n")
+			g.printf("if _, err := pa.ParseRaw(); err != nil {\n")
+			g.printf(
+				"return %s, fmt.Errorf(%q, err)\n",
+				zero,
+				"skip "+strings.ReplaceAll(f.key, "%", "%%")+" error: %w",
+			)
+			g.printf("}\n\n")
+			continue
+		}
+		path := ""
+		if g.paths && f.typ.validated() {
+			path = strconv.Quote(mocjson.KeyPath(f.key))
+		}
+		g.writeParse(f.typ, "ret."+f.name+" = %s", zero, f.key, path)
+		g.writeChecks(st, f, zero)
+		g.printf("\n")
+	}
+	g.printf("default:\n")
+	g.printf("return %s, errors.New(\"unknown key\")\n", zero)
+	g.printf("}\n\n")
+
+	g.printf("switch lx.NextTokenType() {\n")
+	g.printf("case mocjson.TokenTypeEndObject:\n")
+	g.printf("lx.ExpectEndObject()\n")
+	g.printf("goto Validate\n\n")
+	g.printf("case mocjson.TokenTypeValueSeparator:\n")
+	g.printf("lx.ExpectValueSeparator()\n")
+	g.printf("if lx.TrailingComma(mocjson.TokenTypeEndObject) {\n")
+	g.printf("lx.ExpectEndObject()\n")
+	g.printf("goto Validate\n")
+	g.printf("}\n\n")
+	g.printf("default:\n")
+	g.printf("return %s, errors.New(\"expect value separator or end object\")\n", zero)
+	g.printf("}\n")
+	g.printf("}\n\n")
+
+	g.printf("Validate:\n")
+	for i, f := range st.fields {
+		switch {
+		case f.defaultValue != "":
+			g.printf("if seen&(1<<%d) == 0 {\n", i+1)
+			g.printf("ret.%s = %s\n", f.name, f.defaultValue)
+			g.printf("}\n")
+
+		case !f.optional && !f.discriminator:
+			g.printf("if seen&(1<<%d) == 0 {\n", i+1)
+			g.printf("return %s, errors.New(%q)\n", zero, "missing "+f.key)
+			g.printf("}\n")
+		}
+	}
+	g.printf("\nreturn ret, nil\n")
+	g.printf("}\n\n")
+}
+
+func (g *generator) writeUnionParser(u *unionType) {
+	g.imports["fmt"] = true
+
+	key := strings.ReplaceAll(u.key, "%", "%%")
+
+	g.printf("func %s(pa *mocjson.Parser) (%s, error) {\n", parseFunc(u.name), u.name)
+	g.printf("lx := pa.Lexer()\n\n")
+	g.printf("if lx.NextTokenType() == mocjson.TokenTypeNull {\n")
+	g.printf("lx.ExpectNull()\n")
+	g.printf("return nil, nil\n")
+	g.printf("}\n\n")
+	g.printf("d, raw, err := pa.ParseDiscriminator(%q)\n", u.key)
+	g.printf("if err != nil {\n")
+	g.printf("return nil, fmt.Errorf(%q, err)\n", "parse "+key+" error: %w")
+	g.printf("}\n\n")
+	g.printf("sub := pa.NewSubParser(raw)\n\n")
+
+	g.printf("switch d {\n")
+	for _, v := range u.variants {
+		values := make([]string, len(v.values))
+		for i, s := range v.values {
+			values[i] = strconv.Quote(s)
+		}
+
+		g.printf("case %s:\n", strings.Join(values, ", "))
+		g.printf("v, err := %s(&sub)\n", v.st.parseFunc())
+		g.printf("if err != nil {\n")
+		g.printf("return nil, fmt.Errorf(%q, d, err)\n", "parse "+key+" %q error: %w")
+		g.printf("}\n")
+		if v.pointer {
+			g.printf("return &v, nil\n\n")
+		} else {
+			g.printf("return v, nil\n\n")
+		}
+	}
+	g.printf("default:\n")
+	g.printf("return nil, fmt.Errorf(%q, d)\n", "unknown "+key+" %q")
+	g.printf("}\n")
+	g.printf("}\n\n")
+}
+
+// writeParse writes the statements which parse a value of t and assign it with
+// the format assign. On error, they return zero and the error about what, with
+// the path segment of the expression path prepended unless path is "".
+func (g *generator) writeParse(t *goType, assign, zero, what, path string) {
+	g.imports["fmt"] = true
+
+	if t.kind == typePointer {
+		g.printf("if lx.NextTokenType() == mocjson.TokenTypeNull {\n")
+		g.printf("lx.ExpectNull()\n")
+		g.printf(assign+"\n", "nil")
+		g.printf("} else {\n")
+		g.writeParse(t.elem, strings.ReplaceAll(assign, "%s", "&%s"), zero, what, path)
+		g.printf("}\n")
+		return
+	}
+
+	if t.kind == typeDecode {
+		g.printf("var v %s\n", t)
+		g.printf("if err := pa.Decode(&v); err != nil {\n")
+	} else {
+		g.printf("v, err := %s\n", g.parseExpr(t))
+		g.printf("if err != nil {\n")
+	}
+	errExpr := "err"
+	if path != "" {
+		errExpr = "mocjson.PrependPath(err, " + path + ")"
+	}
+	g.printf(
+		"return %s, fmt.Errorf(%q, %s)\n",
+		zero,
+		"parse "+strings.ReplaceAll(what, "%", "%%")+" error: %w",
+		errExpr,
+	)
+	g.printf("}\n")
+	g.printf(assign+"\n", "v")
+}
+
+// writeChecks writes the statements which check the validation options of the
+// field f after it is parsed. On error, they return zero and the
+// ValidationError.
+func (g *generator) writeChecks(st *structType, f structField, zero string) {
+	r := f.rules
+	if r == nil {
+		return
+	}
+
+	v := "ret." + f.name
+	guard := ""
+	if r.pointer {
+		if r.nonempty {
+			g.writeCheck(f, zero, v+" == nil", "nonempty", strconv.Quote("null"))
+		}
+		guard = v + " != nil && "
+		v = "*" + v
+	}
+
+	switch r.kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		g.writeNumberChecks(f, zero, guard, v)
+		g.writeEnumCheck(f, zero, guard, "strconv.FormatInt(int64("+v+"), 10)")
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		g.writeNumberChecks(f, zero, guard, v)
+		g.writeEnumCheck(f, zero, guard, "strconv.FormatUint(uint64("+v+"), 10)")
+
+	case reflect.Float32, reflect.Float64:
+		g.writeNumberChecks(f, zero, guard, v)
+
+	case reflect.String:
+		if r.nonempty || r.hasMinLength || r.hasMaxLength {
+			g.imports["unicode/utf8"] = true
+		}
+		g.writeLengthChecks(f, zero, guard, "utf8.RuneCountInString("+v+")")
+
+		if r.pattern != "" {
+			g.imports["strconv"] = true
+			g.writeCheck(
+				f,
+				zero,
+				guard+"!"+st.patternVar(f)+".MatchString("+v+")",
+				"pattern",
+				"strconv.Quote("+v+") + "+strconv.Quote(" does not match "+r.pattern),
+			)
+		}
+		g.writeEnumCheck(f, zero, guard, v)
+
+	case reflect.Slice, reflect.Map:
+		g.writeLengthChecks(f, zero, guard, "len("+v+")")
+	}
+}
+
+func (g *generator) writeNumberChecks(f structField, zero, guard, v string) {
+	r := f.rules
+	n := "float64(" + v + ")"
+
+	for _, c := range []struct {
+		rule  string
+		has   bool
+		limit float64
+		op    string
+		msg   string
+	}{
+		{"min", r.hasMin, r.min, " < ", " is less than "},
+		{"max", r.hasMax, r.max, " > ", " is greater than "},
+	} {
+		if !c.has {
+			continue
+		}
+		g.writeCheck(
+			f,
+			zero,
+			guard+n+c.op+strconv.FormatFloat(c.limit, 'g', -1, 64),
+			c.rule,
+			fmt.Sprintf("fmt.Sprintf(%q, %s)", "%v"+c.msg+fmt.Sprint(c.limit), n),
+		)
+	}
+}
+
+func (g *generator) writeLengthChecks(f structField, zero, guard, n string) {
+	r := f.rules
+
+	if r.nonempty {
+		g.writeCheck(f, zero, guard+n+" == 0", "nonempty", strconv.Quote("empty"))
+	}
+	for _, c := range []struct {
+		rule  string
+		has   bool
+		limit int
+		op    string
+		msg   string
+	}{
+		{"minLength", r.hasMinLength, r.minLength, " < ", " is less than "},
+		{"maxLength", r.hasMaxLength, r.maxLength, " > ", " is greater than "},
+	} {
+		if !c.has {
+			continue
+		}
+		g.writeCheck(
+			f,
+			zero,
+			guard+n+c.op+strconv.Itoa(c.limit),
+			c.rule,
+			fmt.Sprintf("fmt.Sprintf(%q, %s)", "length %d"+c.msg+strconv.Itoa(c.limit), n),
+		)
+	}
+}
+
+// writeEnumCheck writes the check of the enum option on the string s.
+func (g *generator) writeEnumCheck(f structField, zero, guard, s string) {
+	enum := f.rules.enum
+	if enum == nil {
+		return
+	}
+	g.imports["slices"] = true
+	g.imports["strconv"] = true
+
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		values[i] = strconv.Quote(e)
+	}
+
+	g.writeCheck(
+		f,
+		zero,
+		guard+"!slices.Contains([]string{"+strings.Join(values, ", ")+"}, "+s+")",
+		"enum",
+		"strconv.Quote("+s+") + "+strconv.Quote(fmt.Sprintf(" is not one of %q", enum)),
+	)
+}
+
+// writeCheck writes the statement which returns zero and the ValidationError
+// of the rule with the message msg if cond holds.
+func (g *generator) writeCheck(f structField, zero, cond, rule, msg string) {
+	g.printf("if %s {\n", cond)
+	g.printf("return %s, fmt.Errorf(%q, &mocjson.ValidationError{\n", zero,
+		"parse "+strings.ReplaceAll(f.key, "%", "%%")+" error: %w")
+	g.printf("Path: %q,\n", mocjson.KeyPath(f.key))
+	g.printf("Rule: %q,\n", rule)
+	g.printf("Msg: %s,\n", msg)
+	g.printf("})\n")
+	g.printf("}\n")
+}
+
+// parseExpr returns the expression which parses a value of t, which is not a
+// pointer.
+func (g *generator) parseExpr(t *goType) string {
+	switch t.kind {
+	case typeBool:
+		return "pa.ParseBool()"
+	case typeInt64:
+		return "pa.ParseInt64()"
+	case typeFloat64:
+		return "pa.ParseFloat64()"
+	case typeString:
+		return "pa.ParseString()"
+	case typeSlice:
+		return "parse" + g.helper(t) + "(pa)"
+	case typeStruct:
+		return t.st.parseFunc() + "(pa)"
+	case typeUnion:
+		return parseFunc(t.u.name) + "(pa)"
+	}
+
+	return "pa.ParseValue()"
+}
+
+// helper registers the helper function of t and returns its name.
+func (g *generator) helper(t *goType) string {
+	name := t.funcName()
+	if !g.helped[name] {
+		g.helped[name] = true
+		g.helpers = append(g.helpers, t)
+	}
+
+	return name
+}
+
+func (g *generator) writeHelper(t *goType) {
+	name := t.funcName()
+
+	g.printf("func parse%s(pa *mocjson.Parser) (%s, error) {\n", name, t)
+	g.printf("lx := pa.Lexer()\n\n")
+	g.printf("if lx.NextTokenType() == mocjson.TokenTypeNull {\n")
+	g.printf("lx.ExpectNull()\n")
+	g.printf("return nil, nil\n")
+	g.printf("}\n\n")
+	g.printf("if !lx.ExpectBeginArray() {\n")
+	g.printf("return nil, errors.New(\"expect begin array\")\n")
+	g.printf("}\n\n")
+	g.printf("ret := make(%s, 0)\n\n", t)
+	g.printf("if lx.NextTokenType() == mocjson.TokenTypeEndArray {\n")
+	g.printf("lx.ExpectEndArray()\n")
+	g.printf("return ret, nil\n")
+	g.printf("}\n\n")
+	g.printf("for {\n")
+	path := ""
+	if g.paths && t.elem.validated() {
+		path = "mocjson.IndexPath(len(ret))"
+	}
+	g.writeParse(t.elem, "ret = append(ret, %s)", "nil", "value", path)
+	g.printf("\n")
+	g.printf("switch lx.NextTokenType() {\n")
+	g.printf("case mocjson.TokenTypeEndArray:\n")
+	g.printf("lx.ExpectEndArray()\n")
+	g.printf("return ret, nil\n\n")
+	g.printf("case mocjson.TokenTypeValueSeparator:\n")
+	g.printf("lx.ExpectValueSeparator()\n")
+	g.printf("if lx.TrailingComma(mocjson.TokenTypeEndArray) {\n")
+	g.printf("lx.ExpectEndArray()\n")
+	g.printf("return ret, nil\n")
+	g.printf("}\n\n")
+	g.printf("default:\n")
+	g.printf("return nil, errors.New(\"expect value separator or end array\")\n")
+	g.printf("}\n")
+	g.printf("}\n")
+	g.printf("}\n\n")
+}
+
+// commentKey returns the key as is in comments, or quoted if it is not
+// printable.
+func commentKey(k string) string {
+	if strings.IndexFunc(k, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+		return strconv.Quote(k)
+	}
+
+	return k
+}
+
+// initialisms are the words which are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"API": true, "CPU": true, "CSS": true, "DNS": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true, "TTL": true,
+	"UI": true, "URI": true, "URL": true, "UTC": true, "UUID": true, "XML": true,
+}
+
+// goName converts the key into an exported Go name, e.g. "event_id" into
+// "EventID" and "object2Array" into "Object2Array".
+func goName(key string) string {
+	var words []string
+	var word []rune
+
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+
+	for _, r := range key {
+		switch {
+		case unicode.IsUpper(r):
+			if len(word) > 0 && !unicode.IsUpper(word[len(word)-1]) {
+				flush()
+			}
+			word = append(word, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// The last upper case letter of an initialism starts a word, e.g.
+			// "HTTPServer".
+			if n := len(word); unicode.IsLetter(r) && n >= 2 &&
+				unicode.IsUpper(word[n-1]) && unicode.IsUpper(word[n-2]) {
+				last := word[n-1]
+				word = word[:n-1]
+				flush()
+				word = []rune{last}
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if up := strings.ToUpper(w); initialisms[up] {
+			b.WriteString(up)
+			continue
+		}
+		r := []rune(strings.ToLower(w))
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+
+	name := b.String()
+	switch {
+	case name == "":
+		return "Field"
+	case !unicode.IsLetter([]rune(name)[0]):
+		return "X" + name
+	}
+
+	return name
+}
+
+// elemName returns the name of the items of the array name, e.g. "Item" for
+// "Items" and "DataItem" for "Data".
+func elemName(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+
+	return name + "Item"
+}
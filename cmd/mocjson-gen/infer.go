@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/high-moctane/mocjson-go"
+)
+
+// kinds is the set of the JSON kinds observed at a position in the samples.
+type kinds uint8
+
+const (
+	kindNull kinds = 1 << iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	kindArray
+	kindObject
+)
+
+// shape is the union of the values observed at a position in the samples.
+type shape struct {
+	kinds kinds
+
+	// elem is the shape of the items of the arrays.
+	elem *shape
+
+	// fields are the keys of the objects in the order of appearance.
+	fields  []*fieldShape
+	byKey   map[string]*fieldShape
+	objects int
+}
+
+type fieldShape struct {
+	key   string
+	shape *shape
+
+	// count is the number of the objects which have the key.
+	count int
+}
+
+// optional reports whether some objects do not have the field.
+func (s *shape) optional(f *fieldShape) bool {
+	return f.count < s.objects
+}
+
+// observe reads the next value from pa and merges it into s.
+func (s *shape) observe(pa *mocjson.Parser) error {
+	lx := pa.Lexer()
+
+	switch lx.NextTokenType() {
+	case mocjson.TokenTypeNull:
+		if !lx.ExpectNull() {
+			return errors.New("expect null")
+		}
+		s.kinds |= kindNull
+
+	case mocjson.TokenTypeBool:
+		if _, ok := lx.ExpectBool(); !ok {
+			return errors.New("expect bool")
+		}
+		s.kinds |= kindBool
+
+	case mocjson.TokenTypeNumber:
+		b, ok := lx.ExpectNumberBytes()
+		if !ok {
+			return errors.New("expect number")
+		}
+		// The integers which overflow int64 are floats.
+		if _, err := strconv.ParseInt(string(b), 10, 64); err == nil {
+			s.kinds |= kindInt
+		} else {
+			s.kinds |= kindFloat
+		}
+
+	case mocjson.TokenTypeString:
+		if _, ok := lx.ExpectString(); !ok {
+			return errors.New("expect string")
+		}
+		s.kinds |= kindString
+
+	case mocjson.TokenTypeBeginArray:
+		return s.observeArray(pa)
+
+	case mocjson.TokenTypeBeginObject:
+		return s.observeObject(pa)
+
+	default:
+		return errors.New("expect value")
+	}
+
+	return nil
+}
+
+func (s *shape) observeArray(pa *mocjson.Parser) error {
+	lx := pa.Lexer()
+
+	if !lx.ExpectBeginArray() {
+		return errors.New("expect begin array")
+	}
+
+	s.kinds |= kindArray
+	if s.elem == nil {
+		s.elem = new(shape)
+	}
+
+	if lx.NextTokenType() == mocjson.TokenTypeEndArray {
+		lx.ExpectEndArray()
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		if err := s.elem.observe(pa); err != nil {
+			return fmt.Errorf("[%d]: %w", i, err)
+		}
+
+		switch lx.NextTokenType() {
+		case mocjson.TokenTypeEndArray:
+			lx.ExpectEndArray()
+			return nil
+
+		case mocjson.TokenTypeValueSeparator:
+			lx.ExpectValueSeparator()
+
+		default:
+			return errors.New("expect value separator or end array")
+		}
+	}
+}
+
+func (s *shape) observeObject(pa *mocjson.Parser) error {
+	lx := pa.Lexer()
+
+	if !lx.ExpectBeginObject() {
+		return errors.New("expect begin object")
+	}
+
+	s.kinds |= kindObject
+	s.objects++
+	if s.byKey == nil {
+		s.byKey = make(map[string]*fieldShape)
+	}
+
+	if lx.NextTokenType() == mocjson.TokenTypeEndObject {
+		lx.ExpectEndObject()
+		return nil
+	}
+
+	seen := make(map[string]bool)
+
+	for {
+		k, ok := lx.ExpectString()
+		if !ok {
+			return errors.New("expect string")
+		}
+		if seen[k] {
+			return fmt.Errorf("duplicate key %q", k)
+		}
+		seen[k] = true
+
+		if !lx.ExpectNameSeparator() {
+			return errors.New("expect name separator")
+		}
+
+		f, ok := s.byKey[k]
+		if !ok {
+			f = &fieldShape{key: k, shape: new(shape)}
+			s.byKey[k] = f
+			s.fields = append(s.fields, f)
+		}
+		f.count++
+
+		if err := f.shape.observe(pa); err != nil {
+			return fmt.Errorf("%q: %w", k, err)
+		}
+
+		switch lx.NextTokenType() {
+		case mocjson.TokenTypeEndObject:
+			lx.ExpectEndObject()
+			return nil
+
+		case mocjson.TokenTypeValueSeparator:
+			lx.ExpectValueSeparator()
+
+		default:
+			return errors.New("expect value separator or end object")
+		}
+	}
+}
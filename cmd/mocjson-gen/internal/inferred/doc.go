@@ -0,0 +1,6 @@
+// Package inferred holds the code generated by mocjson-gen infer from the
+// samples in testdata. It is compared with the output of the command in the
+// tests.
+package inferred
+
+//go:generate go run ../.. infer -package inferred -type Order -o order.go ../../testdata/order1.json ../../testdata/order2.json
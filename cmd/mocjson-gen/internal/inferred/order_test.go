@@ -0,0 +1,187 @@
+package inferred
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/high-moctane/mocjson-go"
+)
+
+// TestParseOrder checks that the generated parser agrees with Decode.
+func TestParseOrder(t *testing.T) {
+	t.Parallel()
+
+	sample, err := os.ReadFile("../../testdata/order1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"sample", string(sample), false},
+		{
+			"optional",
+			`{"id":1,"customer":{"name":"a","email":null},"items":null,"tags":[],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":1,` +
+				`"extra":{"a":[1]},"shipped":true}`,
+			false,
+		},
+		{
+			"missing id",
+			`{"customer":{"name":"a","email":null},"items":[],"tags":[],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":1,` +
+				`"extra":null}`,
+			true,
+		},
+		{
+			"unknown key",
+			`{"id":1,"customer":{"name":"a","email":null,"age":1},"items":[],"tags":[],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":1,` +
+				`"extra":null}`,
+			true,
+		},
+		{
+			"duplicate key",
+			`{"id":1,"id":1,"customer":{"name":"a","email":null},"items":[],"tags":[],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":1,` +
+				`"extra":null}`,
+			true,
+		},
+		{
+			"float id",
+			`{"id":1.5,"customer":{"name":"a","email":null},"items":[],"tags":[],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":1,` +
+				`"extra":null}`,
+			true,
+		},
+		{
+			"invalid item",
+			`{"id":1,"customer":{"name":"a","email":null},"items":[{"sku":"a"}],"tags":[],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":1,` +
+				`"extra":null}`,
+			true,
+		},
+		{
+			"trailing comma",
+			`{"id":1,"customer":{"name":"a","email":null},"items":[],"tags":["a",],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":1,` +
+				`"extra":null}`,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := mocjson.NewParser(strings.NewReader(tt.input))
+			got, err := ParseOrder(&pa)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOrder error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			pa = mocjson.NewParser(strings.NewReader(tt.input))
+			var want Order
+			decodeErr := pa.Decode(&want)
+			if (decodeErr != nil) != tt.wantErr {
+				t.Fatalf("Decode error = %v, wantErr %v", decodeErr, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseOrder = %+v, Decode = %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestParseOrder_JSON5 checks that the generated parser accepts JSON5 as
+// Decode does.
+func TestParseOrder_JSON5(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			"identifier keys",
+			`{id:1,customer:{name:"a",email:null},items:[],tags:[],` +
+				`coupon:null,metadata:{source:"s"},createdAt:"",HTTPStatus:1,` +
+				`extra:null}`,
+			false,
+		},
+		{
+			"trailing commas",
+			`{"id":1,"customer":{"name":"a","email":null,},"items":[],"tags":["a",],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":1,` +
+				`"extra":null,}`,
+			false,
+		},
+		{
+			"numbers",
+			`{"id":0x10,"customer":{"name":"a","email":null},"items":[],"tags":[],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":+2e2,` +
+				`"extra":null}`,
+			false,
+		},
+		{
+			"comments",
+			`{/* id */"id":1,"customer":{"name":"a","email":null},"items":[],"tags":[],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":1,` +
+				"\"extra\":null // end\n}",
+			false,
+		},
+		{
+			"two trailing commas",
+			`{"id":1,"customer":{"name":"a","email":null},"items":[],"tags":["a",,],` +
+				`"coupon":null,"metadata":{"source":"s"},"createdAt":"","HTTPStatus":1,` +
+				`"extra":null}`,
+			true,
+		},
+	}
+
+	opts := mocjson.ParserOptions{JSON5: true}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := mocjson.NewParserWithOptions(strings.NewReader(tt.input), opts)
+			got, err := ParseOrder(&pa)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOrder error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			pa = mocjson.NewParserWithOptions(strings.NewReader(tt.input), opts)
+			var want Order
+			decodeErr := pa.Decode(&want)
+			if (decodeErr != nil) != tt.wantErr {
+				t.Fatalf("Decode error = %v, wantErr %v", decodeErr, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseOrder = %+v, Decode = %+v", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkParseOrder(b *testing.B) {
+	sample, err := os.ReadFile("../../testdata/order1.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for b.Loop() {
+		pa := mocjson.NewParser(strings.NewReader(string(sample)))
+		if _, err := ParseOrder(&pa); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// Package parsed holds the code generated by mocjson-gen parser from the types
+// in types.go. It is compared with the output of the command in the tests.
+package parsed
+
+//go:generate go run ../.. parser -o types_parser.go types.go
@@ -0,0 +1,87 @@
+package parsed
+
+import (
+	"time"
+
+	"github.com/high-moctane/mocjson-go"
+)
+
+type Order struct {
+	ID       int64    `json:"id"                mocjson:"min=1"`
+	Customer Customer `json:"customer"`
+	Items    []Item   `json:"items"             mocjson:"maxLength=3"`
+	Note     *string  `json:"note,omitempty"    mocjson:"maxLength=8"`
+	Gift     bool     `json:"gift"`
+	Currency string   `json:"currency"          mocjson:"default=\"JPY\",pattern=^[A-Z]{3}$"`
+	Priority int8     `json:"priority"          mocjson:"default=-1,enum=-1|0|1|2"`
+	Express  bool     `json:"express"           mocjson:"default=true"`
+
+	// The fields below are read with Decode.
+	Quantity int32             `json:"quantity"          mocjson:"min=1,max=100"`
+	Labels   map[string]string `json:"labels,omitempty"  mocjson:"maxLength=2"`
+	PlacedAt time.Time         `json:"placedAt"`
+	Total    Money             `json:"total"`
+
+	Events []Event `json:"events" mocjson:"nonempty"`
+	Last   Event   `json:"last,omitempty"`
+	Extra  any     `json:"extra,omitempty"`
+
+	// The fields below are not decoded.
+	Skipped  string `json:"-"`
+	internal string
+}
+
+type Customer struct {
+	Name  string  `json:"name"  mocjson:"nocase,nonempty"`
+	Email *string `json:"email" mocjson:"alias=mail|e_mail,nocase,minLength=3"`
+}
+
+type Item struct {
+	SKU      string  `json:"sku"              mocjson:"alias=item_sku,pattern=^[a-z]+$"`
+	Price    float64 `json:"price"            mocjson:"min=0"`
+	Count    uint16  `json:"count"            mocjson:"default=1e2,max=1000"`
+	Discount float32 `json:"discount"         mocjson:"default=0.1,min=0,max=1"`
+	Tax      float64 `json:"tax"              mocjson:"default=1e-1"`
+	Parent   *Item   `json:"parent,omitempty" mocjson:"nonempty"`
+}
+
+// Money is an amount such as "1.50", which is read by its UnmarshalMocJSON
+// method.
+type Money struct {
+	Amount string
+}
+
+func (m *Money) UnmarshalMocJSON(pa *mocjson.Parser) error {
+	s, err := pa.ParseString()
+	if err != nil {
+		return err
+	}
+
+	m.Amount = s
+	return nil
+}
+
+type Event interface {
+	event()
+}
+
+// Created does not have the field of the discriminator key.
+type Created struct {
+	At string `json:"at" mocjson:"alias=time"`
+}
+
+func (Created) event() {}
+
+// Canceled has the field which matches the discriminator key.
+type Canceled struct {
+	Kind   string `json:"Kind"   mocjson:"nocase"`
+	Reason string `json:"reason" mocjson:"enum=late|other"`
+}
+
+func (*Canceled) event() {}
+
+func init() {
+	mocjson.RegisterVariant[Event, Created]("kind", "created")
+	mocjson.RegisterVariant[Event, Created]("kind", "new")
+	mocjson.RegisterVariant[Event, *Canceled]("kind", "canceled")
+}
@@ -0,0 +1,295 @@
+package parsed
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/high-moctane/mocjson-go"
+)
+
+const orderInput = `{"id":1,"customer":{"name":"a","email":null},"items":[{"sku":"x","price":1.5}],` +
+	`"gift":false,"quantity":2,"placedAt":"2025-01-02T03:04:05Z","total":"1.50",` +
+	`"events":[{"kind":"created","at":"t"},{"at":"t","kind":"new"},` +
+	`{"kind":"canceled","reason":"late"},null]`
+
+// TestParseOrder checks that the generated parser agrees with Decode.
+func TestParseOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		json5   bool
+		wantErr bool
+	}{
+		{"required", orderInput + `}`, false, false},
+		{
+			"optional",
+			orderInput + `,"note":"n","labels":{"a":"b"},"last":{"kind":"created","at":"t"},` +
+				`"extra":[1,{"a":null}]}`,
+			false,
+			false,
+		},
+		{
+			"null optional",
+			orderInput + `,"note":null,"labels":null,"last":null,"extra":null}`,
+			false,
+			false,
+		},
+		{
+			"null items",
+			strings.Replace(orderInput, `[{"sku":"x","price":1.5}]`, `null`, 1) + `}`,
+			false,
+			false,
+		},
+		{"missing gift", strings.Replace(orderInput, `"gift":false,`, ``, 1) + `}`, false, true},
+		{"unknown key", orderInput + `,"unknown":1}`, false, true},
+		{"duplicate key", orderInput + `,"id":1}`, false, true},
+		{"skipped key", orderInput + `,"Skipped":"s"}`, false, true},
+		{
+			"quantity overflow",
+			strings.Replace(orderInput, `"quantity":2`, `"quantity":2147483648`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"integral quantity",
+			strings.Replace(orderInput, `"quantity":2`, `"quantity":2e1`, 1) + `}`,
+			false,
+			false,
+		},
+		{
+			"invalid time",
+			strings.Replace(orderInput, `2025-01-02T03:04:05Z`, `now`, 1) + `}`,
+			false,
+			true,
+		},
+		{"invalid total", strings.Replace(orderInput, `"1.50"`, `1.5`, 1) + `}`, false, true},
+		{"unknown kind", orderInput + `,"last":{"kind":"moved"}}`, false, true},
+		{"missing kind", orderInput + `,"last":{"at":"t"}}`, false, true},
+		{"invalid variant", orderInput + `,"last":{"kind":"created"}}`, false, true},
+		{
+			"aliases",
+			strings.NewReplacer(`"sku"`, `"item_sku"`, `"at"`, `"time"`).Replace(orderInput) + `}`,
+			false,
+			false,
+		},
+		{
+			"nocase",
+			strings.NewReplacer(`"name"`, `"NAME"`, `"email"`, `"E_Mail"`).
+				Replace(orderInput) +
+				`}`,
+			false,
+			false,
+		},
+		{
+			"nocase key is not exact",
+			strings.Replace(orderInput, `"sku"`, `"SKU"`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"duplicate alias",
+			strings.Replace(orderInput, `"sku":"x"`, `"sku":"x","item_sku":"y"`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"duplicate nocase",
+			strings.Replace(orderInput, `"name":"a"`, `"name":"a","Name":"b"`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"defaults",
+			strings.Replace(
+				orderInput,
+				`"price":1.5`,
+				`"price":1.5,"count":3,"discount":0.5,"tax":0`,
+				1,
+			) +
+				`,"currency":"USD","priority":2,"express":false}`,
+			false,
+			false,
+		},
+		{"invalid default field", orderInput + `,"priority":128}`, false, true},
+		{"min", strings.Replace(orderInput, `"id":1`, `"id":0`, 1) + `}`, false, true},
+		{
+			"max of decoded",
+			strings.Replace(orderInput, `"quantity":2`, `"quantity":101`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"nested min",
+			strings.Replace(orderInput, `"price":1.5`, `"price":-1`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"float32 max",
+			strings.Replace(orderInput, `"price":1.5`, `"price":1.5,"discount":1.5`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"uint max",
+			strings.Replace(orderInput, `"price":1.5`, `"price":1.5,"count":1001`, 1) + `}`,
+			false,
+			true,
+		},
+		{"enum", orderInput + `,"priority":3}`, false, true},
+		{"pattern", orderInput + `,"currency":"usd"}`, false, true},
+		{
+			"nested pattern",
+			strings.Replace(orderInput, `"sku":"x"`, `"sku":"X"`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"nonempty string",
+			strings.Replace(orderInput, `"name":"a"`, `"name":""`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"minLength of pointer",
+			strings.Replace(orderInput, `"email":null`, `"email":"ab"`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"minLength of runes",
+			strings.Replace(orderInput, `"email":null`, `"email":"あいう"`, 1) + `}`,
+			false,
+			false,
+		},
+		{"maxLength of pointer", orderInput + `,"note":"123456789"}`, false, true},
+		{
+			"maxLength of slice",
+			strings.Replace(orderInput, `[{"sku":"x","price":1.5}]`, `[{"sku":"x","price":1.5},`+
+				`{"sku":"x","price":1.5},{"sku":"x","price":1.5},{"sku":"x","price":1.5}]`, 1) +
+				`}`,
+			false,
+			true,
+		},
+		{"maxLength of map", orderInput + `,"labels":{"a":"","b":"","c":""}}`, false, true},
+		{
+			"nonempty slice",
+			orderInput[:strings.Index(orderInput, `"events"`)] + `"events":[]}`,
+			false,
+			true,
+		},
+		{
+			"nonempty pointer",
+			strings.Replace(orderInput, `"price":1.5`, `"price":1.5,"parent":null`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"recursive",
+			strings.Replace(
+				orderInput,
+				`"price":1.5`,
+				`"price":1.5,"parent":{"sku":"y","price":-1}`,
+				1,
+			) +
+				`}`,
+			false,
+			true,
+		},
+		{
+			"variant enum",
+			strings.Replace(orderInput, `"reason":"late"`, `"reason":"r"`, 1) + `}`,
+			false,
+			true,
+		},
+		{
+			"json5",
+			`{id:0x1,customer:{name:'a',email:null,},items:[],gift:true,quantity:+2,` +
+				`placedAt:"2025-01-02T03:04:05Z",total:"1.50",` +
+				`events:[{kind:'canceled',reason:'late',},],last:{at:"t",kind:"new"},}`,
+			true,
+			false,
+		},
+		{"json5 disabled", `{id:1}`, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := mocjson.ParserOptions{JSON5: tt.json5}
+
+			pa := mocjson.NewParserWithOptions(strings.NewReader(tt.input), opts)
+			got, err := ParseOrder(&pa)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOrder error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			pa = mocjson.NewParserWithOptions(strings.NewReader(tt.input), opts)
+			var want Order
+			decodeErr := pa.Decode(&want)
+			if (decodeErr != nil) != tt.wantErr {
+				t.Fatalf("Decode error = %v, wantErr %v", decodeErr, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseOrder = %+v, Decode = %+v", got, want)
+			}
+
+			var gotVE, wantVE *mocjson.ValidationError
+			errors.As(err, &gotVE)
+			errors.As(decodeErr, &wantVE)
+			if !reflect.DeepEqual(gotVE, wantVE) {
+				t.Errorf("ParseOrder error = %v, Decode error = %v", err, decodeErr)
+			}
+		})
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  Event
+	}{
+		{"created", `{"kind":"created","at":"t"}`, Created{At: "t"}},
+		{"second value", `{"at":"t","kind":"new"}`, Created{At: "t"}},
+		{"alias", `{"kind":"created","time":"t"}`, Created{At: "t"}},
+		{
+			"pointer",
+			`{"reason":"late","kind":"canceled"}`,
+			&Canceled{Kind: "canceled", Reason: "late"},
+		},
+		{"null", `null`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := mocjson.NewParser(strings.NewReader(tt.input))
+			got, err := ParseEvent(&pa)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+
+			pa = mocjson.NewParser(strings.NewReader(tt.input))
+			var decoded Event
+			if err := pa.Decode(&decoded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, decoded) {
+				t.Errorf("got %#v, Decode = %#v", got, decoded)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Mocjson-gen generates Go code for mocjson.
+//
+// Usage:
+//
+//	mocjson-gen infer [flags] [file ...]
+//	mocjson-gen parser [flags] [file]
+//
+// The infer command reads sample JSON documents from the files, or from the
+// standard input if no files are given, and generates the Go struct types
+// which accept all of them, together with a Parse<Type> function for each
+// type. A file may contain more than one document, such as JSON Lines.
+//
+// The types of the values at the same position in the samples are unified:
+// integers and floats into float64, null and a type T into *T (or T itself
+// for slices), and the other mixes into any. The keys missing from some of the
+// objects are optional and tagged omitempty; the others are required. The
+// samples must be objects or arrays of them.
+//
+// The flags are:
+//
+//	-type name
+//		the name of the root type (default "Root")
+//	-package name
+//		the package name of the generated code (default "main")
+//	-o file
+//		the output file (default the standard output)
+//
+// The parser command reads a Go source file, or the standard input if no file
+// is given, and generates a Parse<Type> function for each struct type in it,
+// which is decoded as Decode does. The fields of the types which are not
+// declared in the file, and of the types which have the UnmarshalMocJSON
+// method, are read with Decode. The options of the mocjson tag are supported.
+// A default value is given to the fields of the basic types only, and it is
+// decoded when the code is generated, so an invalid one is an error of the
+// command. The validation options are checked on the fields of the basic
+// types, the slices, the maps and the pointers to them, and nonempty on any
+// pointer, with the same ValidationErrors as Decode. Embedded fields and the
+// json tag options except omitempty are not supported.
+//
+// An interface type is a union if its variants are registered in the file with
+// mocjson.RegisterVariant, whose arguments are string literals. The variants
+// must be the struct types of the file, and their parsers accept the
+// discriminator key.
+//
+// The flags are:
+//
+//	-o file
+//		the output file (default the standard output)
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/high-moctane/mocjson-go"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "mocjson-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: mocjson-gen infer|parser [flags] [file ...]")
+	}
+
+	switch args[0] {
+	case "infer":
+		return runInfer(args[1:], stdin, stdout)
+	case "parser":
+		return runParser(args[1:], stdin, stdout)
+	}
+
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func runInfer(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("infer", flag.ContinueOnError)
+	typeName := fs.String("type", "Root", "the name of the root type")
+	pkg := fs.String("package", "main", "the package name of the generated code")
+	out := fs.String("o", "", "the output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var s shape
+
+	if fs.NArg() == 0 {
+		if err := observeAll(&s, stdin); err != nil {
+			return fmt.Errorf("stdin: %w", err)
+		}
+	}
+	for _, name := range fs.Args() {
+		if err := observeFile(&s, name); err != nil {
+			return err
+		}
+	}
+
+	src, err := generate(*pkg, *typeName, &s)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := stdout.Write(src)
+		return err
+	}
+
+	return os.WriteFile(*out, src, 0o644)
+}
+
+func runParser(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("parser", flag.ContinueOnError)
+	out := fs.String("o", "", "the output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var (
+		name string
+		src  []byte
+		err  error
+	)
+	switch fs.NArg() {
+	case 0:
+		name = "stdin"
+		src, err = io.ReadAll(stdin)
+	case 1:
+		name = fs.Arg(0)
+		src, err = os.ReadFile(name)
+	default:
+		return errors.New("usage: mocjson-gen parser [flags] [file]")
+	}
+	if err != nil {
+		return err
+	}
+
+	gen, err := generateParsers(name, src)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := stdout.Write(gen)
+		return err
+	}
+
+	return os.WriteFile(*out, gen, 0o644)
+}
+
+func observeFile(s *shape, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := observeAll(s, f); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+// observeAll merges all the documents in r into s.
+func observeAll(s *shape, r io.Reader) error {
+	pa := mocjson.NewParser(r)
+
+	for !pa.Lexer().ExpectEOF() {
+		if err := s.observe(&pa); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRun_Infer(t *testing.T) {
+	t.Parallel()
+
+	want, err := os.ReadFile("internal/inferred/order.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	args := []string{
+		"infer",
+		"-package", "inferred",
+		"-type", "Order",
+		"testdata/order1.json",
+		"testdata/order2.json",
+	}
+	if err := run(args, strings.NewReader(""), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != string(want) {
+		t.Errorf("the output differs from internal/inferred/order.go; run go generate")
+	}
+}
+
+func TestRun_Infer_Types(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			"scalars",
+			`{"b":true,"i":1,"f":1.5,"s":"x","n":null}`,
+			[]string{
+				"B bool `json:\"b\"`",
+				"I int64 `json:\"i\"`",
+				"F float64 `json:\"f\"`",
+				"S string `json:\"s\"`",
+				"N any `json:\"n\"`",
+			},
+		},
+		{
+			"unified numbers",
+			`{"n":1} {"n":1.5} {"n":1e3}`,
+			[]string{"N float64 `json:\"n\"`"},
+		},
+		{
+			"int64 overflow",
+			`{"n":9223372036854775808}`,
+			[]string{"N float64 `json:\"n\"`"},
+		},
+		{
+			"nullable",
+			`{"s":"x","o":{},"a":[1]} {"s":null,"o":null,"a":null}`,
+			[]string{
+				"S *string `json:\"s\"`",
+				"O *O `json:\"o\"`",
+				"A []int64 `json:\"a\"`",
+			},
+		},
+		{
+			"mixed",
+			`{"v":1} {"v":"x"}`,
+			[]string{"V any `json:\"v\"`"},
+		},
+		{
+			"optional",
+			`{"a":1,"b":2} {"a":3}`,
+			[]string{"A int64 `json:\"a\"`", "B int64 `json:\"b,omitempty\"`"},
+		},
+		{
+			"nested arrays",
+			`{"m":[[1,null],[]],"e":[]}`,
+			[]string{
+				"M [][]*int64 `json:\"m\"`",
+				"E []any `json:\"e\"`",
+				"parseNullableInt64Array",
+			},
+		},
+		{
+			"array elements",
+			`{"entries":[{"a":1},{"a":2,"b":true}]}`,
+			[]string{
+				"Entries []Entry `json:\"entries\"`",
+				"type Entry struct",
+				"B bool `json:\"b,omitempty\"`",
+			},
+		},
+		{
+			"root array",
+			`[{"id":"a"}]`,
+			[]string{"type Root []RootItem", "type RootItem struct", "func ParseRoot("},
+		},
+		{
+			"name conflict",
+			`{"user":{"root":{}},"root":{}}`,
+			[]string{"type UserRoot struct", "type RootRoot struct"},
+		},
+		{
+			"key names",
+			`{"event_id":1,"eventId":2,"HTTPServer":3,"1st":4,"":5,"a\nb%d":6}`,
+			[]string{
+				"EventID int64 `json:\"event_id\"`",
+				"EventID2 int64 `json:\"eventId\"`",
+				"HTTPServer int64 `json:\"HTTPServer\"`",
+				"X1st int64 `json:\"1st\"`",
+				"Field int64 `json:\"\"`",
+				`errors.New("missing a\nb%d")`,
+				`"parse a\nb%%d error: %w"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			if err := run([]string{"infer"}, strings.NewReader(tt.input), &out); err != nil {
+				t.Fatal(err)
+			}
+
+			// The fields are aligned by gofmt.
+			got := strings.Join(strings.Fields(out.String()), " ")
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("want %s in:\n%s", w, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRun_Infer_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+	}{
+		{"no command", nil, ""},
+		{"unknown command", []string{"guess"}, ""},
+		{"no file", []string{"infer", "testdata/none.json"}, ""},
+		{"invalid json", []string{"infer"}, `{"a":}`},
+		{"duplicate key", []string{"infer"}, `{"a":1,"a":2}`},
+		{"scalar", []string{"infer"}, `1`},
+		{"no samples", []string{"infer"}, ``},
+		{"too many fields", []string{"infer"}, manyFields(64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			if err := run(tt.args, strings.NewReader(tt.input), &out); err == nil {
+				t.Errorf("got nil, want error:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestRun_Parser(t *testing.T) {
+	t.Parallel()
+
+	want, err := os.ReadFile("internal/parsed/types_parser.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	args := []string{"parser", "internal/parsed/types.go"}
+	if err := run(args, strings.NewReader(""), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != string(want) {
+		t.Errorf("the output differs from internal/parsed/types_parser.go; run go generate")
+	}
+}
+
+func TestRun_Parser_Imports(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		reject string
+	}{
+		{
+			"standard",
+			"package p\nimport \"net/netip\"\ntype T struct { A netip.Addr }",
+			"\"net/netip\"\n",
+			"",
+		},
+		{
+			"version suffix",
+			"package p\nimport \"gopkg.in/yaml.v3\"\ntype T struct { A yaml.Node }",
+			"yaml \"gopkg.in/yaml.v3\"\n",
+			"",
+		},
+		{
+			"named",
+			"package p\nimport t \"time\"\ntype T struct { A t.Time }",
+			"t \"time\"\n",
+			"",
+		},
+		{
+			"pattern",
+			"package p\ntype T struct { A string `mocjson:\"pattern=^a$\"` }",
+			"\"regexp\"\n",
+			"\"unicode/utf8\"",
+		},
+		{
+			"unused",
+			"package p\nimport \"time\"\ntype T struct { A int32 }\nvar d time.Duration",
+			"",
+			"\"time\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			if err := run([]string{"parser"}, strings.NewReader(tt.input), &out); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("the output does not contain %q:\n%s", tt.want, out.String())
+			}
+			if tt.reject != "" && strings.Contains(out.String(), tt.reject) {
+				t.Errorf("the output contains %q:\n%s", tt.reject, out.String())
+			}
+		})
+	}
+}
+
+func TestRun_Parser_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+	}{
+		{"no file", []string{"parser", "testdata/none.go"}, ""},
+		{"two files", []string{"parser", "a.go", "b.go"}, ""},
+		{"invalid go", []string{"parser"}, "package p\ntype T struct {"},
+		{"embedded", []string{"parser"}, "package p\ntype B struct{}\ntype T struct { B }"},
+		{"type parameters", []string{"parser"}, "package p\ntype T[E any] struct { A E }"},
+		{
+			"duplicate key",
+			[]string{"parser"},
+			"package p\ntype T struct { A int `json:\"a\"`; B int `json:\"a\"` }",
+		},
+		{
+			"time option",
+			[]string{"parser"},
+			"package p\nimport \"time\"\ntype T struct { A time.Time `json:\"a,unix\"` }",
+		},
+		{"unknown package", []string{"parser"}, "package p\ntype T struct { A time.Time }"},
+		{
+			"invalid mocjson tag",
+			[]string{"parser"},
+			"package p\ntype T struct { A int `mocjson:\"unknown\"` }",
+		},
+		{
+			"min of string",
+			[]string{"parser"},
+			"package p\ntype T struct { A string `mocjson:\"min=1\"` }",
+		},
+		{
+			"nonempty of int",
+			[]string{"parser"},
+			"package p\ntype T struct { A int `mocjson:\"nonempty\"` }",
+		},
+		{
+			"invalid pattern",
+			[]string{"parser"},
+			"package p\ntype T struct { A string `mocjson:\"pattern=[\"` }",
+		},
+		{
+			"infinite max",
+			[]string{"parser"},
+			"package p\ntype T struct { A float64 `mocjson:\"max=inf\"` }",
+		},
+		{
+			"validation of named type",
+			[]string{"parser"},
+			"package p\ntype S string\ntype T struct { A S `mocjson:\"minLength=1\"` }",
+		},
+		{
+			"validation of pointer to pointer",
+			[]string{"parser"},
+			"package p\ntype T struct { A **int `mocjson:\"min=1\"` }",
+		},
+		{
+			"invalid default by validation",
+			[]string{"parser"},
+			"package p\ntype T struct { A int `mocjson:\"default=0,min=1\"` }",
+		},
+		{
+			"default of pointer",
+			[]string{"parser"},
+			"package p\ntype T struct { A *int `mocjson:\"default=1\"` }",
+		},
+		{
+			"default of struct",
+			[]string{"parser"},
+			"package p\ntype S struct{}\ntype T struct { A S `mocjson:\"default={}\"` }",
+		},
+		{
+			"invalid default",
+			[]string{"parser"},
+			"package p\ntype T struct { A int `mocjson:\"default=\\\"1\\\"\"` }",
+		},
+		{
+			"default overflow",
+			[]string{"parser"},
+			"package p\ntype T struct { A int8 `mocjson:\"default=128\"` }",
+		},
+		{
+			"empty alias",
+			[]string{"parser"},
+			"package p\ntype T struct { A int `mocjson:\"alias=a|\"` }",
+		},
+		{
+			"alias conflict",
+			[]string{"parser"},
+			"package p\ntype T struct { A int `json:\"a\"`; B int `json:\"b\" mocjson:\"alias=a\"` }",
+		},
+		{
+			"variant of unknown interface",
+			[]string{"parser"},
+			"package p\ntype T struct{}\nfunc init() { mocjson.RegisterVariant[I, T](\"kind\", \"t\") }",
+		},
+		{
+			"variant of non-struct",
+			[]string{"parser"},
+			"package p\ntype I interface{}\nfunc init() { mocjson.RegisterVariant[I, int](\"kind\", \"t\") }",
+		},
+		{
+			"variant with non-literal",
+			[]string{"parser"},
+			"package p\ntype I interface{}\ntype T struct{}\nconst k = \"kind\"\n" +
+				"func init() { mocjson.RegisterVariant[I, T](k, \"t\") }",
+		},
+		{
+			"variants with different keys",
+			[]string{"parser"},
+			"package p\ntype I interface{}\ntype T struct{}\ntype U struct{}\n" +
+				"func init() {\n" +
+				"mocjson.RegisterVariant[I, T](\"kind\", \"t\")\n" +
+				"mocjson.RegisterVariant[I, U](\"type\", \"u\")\n" +
+				"}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			if err := run(tt.args, strings.NewReader(tt.input), &out); err == nil {
+				t.Errorf("got nil, want error:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func manyFields(n int) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := range n {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`"f` + strings.Repeat("x", i) + `":1`)
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	var s shape
+	for _, name := range []string{"testdata/order1.json", "testdata/order2.json"} {
+		if err := observeFile(&s, name); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for b.Loop() {
+		if _, err := generate("inferred", "Order", &s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
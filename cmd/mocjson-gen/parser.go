@@ -0,0 +1,664 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/high-moctane/mocjson-go"
+)
+
+// sourceFile is a Go source file which declares the types to be parsed.
+type sourceFile struct {
+	file *ast.File
+
+	// specs are the struct and the interface types in the order of
+	// declaration.
+	specs []*ast.TypeSpec
+
+	structs map[string]*structType
+	unions  map[string]*unionType
+
+	// unmarshalers are the types which have the UnmarshalMocJSON method.
+	// They are read with Decode.
+	unmarshalers map[string]bool
+
+	// imports are the imports which the types read with Decode refer to, with
+	// the names which are added if the paths do not end with them.
+	imports map[string]string
+}
+
+// generateParsers generates the Go source which declares the parsers of the
+// struct types declared in src, and of the interface types whose variants are
+// registered with mocjson.RegisterVariant in src.
+func generateParsers(name string, src []byte) ([]byte, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), name, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	sf := sourceFile{
+		file:         f,
+		structs:      make(map[string]*structType),
+		unions:       make(map[string]*unionType),
+		unmarshalers: make(map[string]bool),
+		imports:      make(map[string]string),
+	}
+	sf.collectTypes()
+
+	if err := sf.collectVariants(); err != nil {
+		return nil, err
+	}
+
+	g := generator{
+		pkg:           f.Name.Name,
+		command:       "parser",
+		helped:        make(map[string]bool),
+		imports:       map[string]bool{"errors": true},
+		paths:         true,
+		sourceImports: sf.imports,
+	}
+
+	for _, spec := range sf.specs {
+		st, ok := sf.structs[spec.Name.Name]
+		if !ok {
+			continue
+		}
+		if err := sf.addFields(st, spec); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, spec := range sf.specs {
+		if st, ok := sf.structs[spec.Name.Name]; ok {
+			g.writeStructParser(st)
+		}
+		if u, ok := sf.unions[spec.Name.Name]; ok {
+			g.writeUnionParser(u)
+		}
+	}
+
+	return g.source()
+}
+
+func (sf *sourceFile) collectTypes() {
+	for _, decl := range sf.file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				spec, ok := spec.(*ast.TypeSpec)
+				if !ok || spec.Assign.IsValid() {
+					continue
+				}
+
+				switch spec.Type.(type) {
+				case *ast.StructType, *ast.InterfaceType:
+					sf.specs = append(sf.specs, spec)
+				}
+			}
+
+		case *ast.FuncDecl:
+			if decl.Recv == nil || decl.Name.Name != "UnmarshalMocJSON" {
+				continue
+			}
+
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if id, ok := recv.(*ast.Ident); ok {
+				sf.unmarshalers[id.Name] = true
+			}
+		}
+	}
+
+	for _, spec := range sf.specs {
+		if _, ok := spec.Type.(*ast.StructType); ok && !sf.unmarshalers[spec.Name.Name] {
+			sf.structs[spec.Name.Name] = &structType{name: spec.Name.Name}
+		}
+	}
+}
+
+// collectVariants collects the calls of mocjson.RegisterVariant in the file.
+func (sf *sourceFile) collectVariants() error {
+	var err error
+
+	ast.Inspect(sf.file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || err != nil {
+			return err == nil
+		}
+
+		fn, ok := call.Fun.(*ast.IndexListExpr)
+		if !ok || !isRegisterVariant(fn.X) {
+			return true
+		}
+
+		err = sf.addVariant(fn, call.Args)
+		return err == nil
+	})
+
+	return err
+}
+
+func isRegisterVariant(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return x.Name == "RegisterVariant"
+	case *ast.SelectorExpr:
+		return x.Sel.Name == "RegisterVariant"
+	}
+
+	return false
+}
+
+func (sf *sourceFile) addVariant(fn *ast.IndexListExpr, args []ast.Expr) error {
+	indices := make([]string, len(fn.Indices))
+	for i, x := range fn.Indices {
+		indices[i] = types.ExprString(x)
+	}
+	call := "RegisterVariant[" + strings.Join(indices, ", ") + "]"
+	if len(fn.Indices) != 2 || len(args) != 2 {
+		return fmt.Errorf("%s: invalid call", call)
+	}
+
+	it, ok := fn.Indices[0].(*ast.Ident)
+	if !ok || !sf.isInterface(it.Name) {
+		return fmt.Errorf("%s: %s is not an interface type of the file", call, indices[0])
+	}
+
+	vt := fn.Indices[1]
+	star, pointer := vt.(*ast.StarExpr)
+	if pointer {
+		vt = star.X
+	}
+	id, ok := vt.(*ast.Ident)
+	if !ok || sf.structs[id.Name] == nil {
+		return fmt.Errorf("%s: %s is not a struct type of the file", call, types.ExprString(vt))
+	}
+	st := sf.structs[id.Name]
+
+	var kv [2]string
+	for i, arg := range args {
+		lit, ok := arg.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return fmt.Errorf("%s: the arguments must be string literals", call)
+		}
+		kv[i], _ = strconv.Unquote(lit.Value)
+	}
+	key, value := kv[0], kv[1]
+
+	u, ok := sf.unions[it.Name]
+	if !ok {
+		u = &unionType{name: it.Name, key: key}
+		sf.unions[it.Name] = u
+	}
+	if u.key != key {
+		return fmt.Errorf("%s: %s is already registered with key %q", call, it.Name, u.key)
+	}
+
+	for i := range u.variants {
+		v := &u.variants[i]
+		if v.st == st && v.pointer == pointer {
+			v.values = append(v.values, value)
+			return nil
+		}
+	}
+	for _, v := range u.variants {
+		if v.st == st {
+			return fmt.Errorf("%s: %s is registered with and without a pointer", call, st.name)
+		}
+	}
+
+	u.variants = append(u.variants, unionVariant{values: []string{value}, st: st, pointer: pointer})
+
+	return nil
+}
+
+func (sf *sourceFile) isInterface(name string) bool {
+	for _, spec := range sf.specs {
+		if _, ok := spec.Type.(*ast.InterfaceType); ok && spec.Name.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addFields adds the fields of the struct type spec to st. The variants of the
+// unions have a field for the discriminator key unless a field matches it.
+func (sf *sourceFile) addFields(st *structType, spec *ast.TypeSpec) error {
+	if spec.TypeParams != nil {
+		return fmt.Errorf("type %s: type parameters are not supported", st.name)
+	}
+
+	keys := make(map[string]bool)
+
+	for _, field := range spec.Type.(*ast.StructType).Fields.List {
+		if len(field.Names) == 0 {
+			return fmt.Errorf(
+				"type %s: embedded field %s is not supported",
+				st.name,
+				types.ExprString(field.Type),
+			)
+		}
+
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			s, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return fmt.Errorf("type %s: invalid tag %s", st.name, field.Tag.Value)
+			}
+			tag = reflect.StructTag(s)
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+
+			f, ok, err := sf.newField(name.Name, field.Type, tag)
+			if err != nil {
+				return fmt.Errorf("type %s: field %s: %w", st.name, name.Name, err)
+			}
+			if !ok {
+				continue
+			}
+
+			if keys[f.key] {
+				return fmt.Errorf("type %s: duplicate key %q", st.name, f.key)
+			}
+			keys[f.key] = true
+
+			for _, a := range f.aliases {
+				if keys[a] {
+					return fmt.Errorf("type %s: field %s: alias %q conflicts", st.name, f.name, a)
+				}
+				keys[a] = true
+			}
+
+			st.fields = append(st.fields, f)
+		}
+	}
+
+	var discriminators []structField
+	for _, spec := range sf.specs {
+		u, ok := sf.unions[spec.Name.Name]
+		if !ok {
+			continue
+		}
+
+		for _, v := range u.variants {
+			if v.st == st && !st.matches(u.key) {
+				keys[u.key] = true
+				discriminators = append(
+					discriminators,
+					structField{key: u.key, optional: true, discriminator: true},
+				)
+			}
+		}
+	}
+	st.fields = append(discriminators, st.fields...)
+
+	if len(st.fields) > maxFields {
+		return fmt.Errorf("type %s: too many fields", st.name)
+	}
+
+	return nil
+}
+
+// newField returns the field of the Go field name. It reports false if the
+// field is skipped by its json tag.
+func (sf *sourceFile) newField(
+	name string,
+	typ ast.Expr,
+	tag reflect.StructTag,
+) (structField, bool, error) {
+	jsonTag := tag.Get("json")
+	if jsonTag == "-" {
+		return structField{}, false, nil
+	}
+
+	key, opts, _ := strings.Cut(jsonTag, ",")
+	if key == "" {
+		key = name
+	}
+
+	f := structField{name: name, key: key}
+
+	for o := range strings.SplitSeq(opts, ",") {
+		switch o {
+		case "":
+		case "omitempty":
+			f.optional = true
+		default:
+			return structField{}, false, fmt.Errorf("the json tag option %q is not supported", o)
+		}
+	}
+
+	mopts, err := mocjson.ParseMocJSONTag(tag.Get("mocjson"))
+	if err != nil {
+		return structField{}, false, err
+	}
+
+	if v, ok := mopts["alias"]; ok {
+		f.aliases = strings.Split(v, "|")
+		if slices.Contains(f.aliases, "") {
+			return structField{}, false, errors.New("empty alias")
+		}
+	}
+
+	_, f.nocase = mopts["nocase"]
+
+	if f.rules, err = newFieldRules(name, typ, tag.Get("mocjson"), mopts); err != nil {
+		return structField{}, false, err
+	}
+
+	if _, ok := mopts["default"]; ok {
+		v, err := defaultLiteral(name, typ, tag.Get("mocjson"))
+		if err != nil {
+			return structField{}, false, err
+		}
+		f.defaultValue = v
+		f.optional = true
+	}
+
+	if err := sf.useImports(typ); err != nil {
+		return structField{}, false, err
+	}
+	f.typ = sf.typeOf(typ)
+
+	return f, true, nil
+}
+
+// useImports adds the imports of the packages which x refers to.
+func (sf *sourceFile) useImports(x ast.Expr) error {
+	var err error
+
+	ast.Inspect(x, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok || err != nil {
+			return err == nil
+		}
+
+		id, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		path, name, ok := sf.lookupImport(id.Name)
+		if !ok {
+			err = fmt.Errorf("unknown package %s", id.Name)
+			return false
+		}
+		if path != mocjsonPath || name != "" {
+			sf.imports[path] = name
+		}
+
+		return false
+	})
+
+	return err
+}
+
+// lookupImport returns the path of the import of the package name, and the
+// name to be written in the import if the path does not end with it. Without
+// the type information, the name of an unnamed import is guessed from the last
+// element of the path, e.g. "yaml" for "gopkg.in/yaml.v3".
+func (sf *sourceFile) lookupImport(name string) (string, string, bool) {
+	for _, spec := range sf.file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		last := path[strings.LastIndex(path, "/")+1:]
+
+		pkg := strings.TrimPrefix(last, "go-")
+		pkg, _, _ = strings.Cut(pkg, ".")
+		pkg = strings.TrimSuffix(pkg, "-go")
+		if spec.Name != nil {
+			pkg = spec.Name.Name
+		}
+
+		switch {
+		case pkg != name:
+			continue
+		case pkg == last:
+			return path, "", true
+		}
+		return path, name, true
+	}
+
+	return "", "", false
+}
+
+// basicTypes are the types of the fields which may have default values.
+var basicTypes = map[string]reflect.Type{
+	"bool":    reflect.TypeFor[bool](),
+	"string":  reflect.TypeFor[string](),
+	"int":     reflect.TypeFor[int](),
+	"int8":    reflect.TypeFor[int8](),
+	"int16":   reflect.TypeFor[int16](),
+	"int32":   reflect.TypeFor[int32](),
+	"int64":   reflect.TypeFor[int64](),
+	"rune":    reflect.TypeFor[rune](),
+	"uint":    reflect.TypeFor[uint](),
+	"uint8":   reflect.TypeFor[uint8](),
+	"uint16":  reflect.TypeFor[uint16](),
+	"uint32":  reflect.TypeFor[uint32](),
+	"uint64":  reflect.TypeFor[uint64](),
+	"uintptr": reflect.TypeFor[uintptr](),
+	"byte":    reflect.TypeFor[byte](),
+	"float32": reflect.TypeFor[float32](),
+	"float64": reflect.TypeFor[float64](),
+}
+
+// defaultLiteral returns the Go literal of the default value of the field name
+// of the type typ with the mocjson tag. The value is decoded by Decode, so that
+// it is checked as Decode does, but only once at generation time.
+func defaultLiteral(name string, typ ast.Expr, mocjsonTag string) (string, error) {
+	var t reflect.Type
+	if id, ok := typ.(*ast.Ident); ok {
+		t = basicTypes[id.Name]
+	}
+	if t == nil {
+		return "", fmt.Errorf("the default option on %s is not supported", types.ExprString(typ))
+	}
+
+	v, err := decodeEmpty(name, t, mocjsonTag)
+	if err != nil {
+		return "", fmt.Errorf("invalid default: %w", err)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.String:
+		return strconv.Quote(v.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+
+	return strconv.FormatFloat(v.Float(), 'g', -1, t.Bits()), nil
+}
+
+// newFieldRules returns the validation options of the field name of the type
+// typ, or nil if there are none. The options are checked by Decode, so that
+// they are allowed on the same types, but the types which are not basic types,
+// slices or maps are not supported except for nonempty on a pointer.
+func newFieldRules(
+	name string,
+	typ ast.Expr,
+	mocjsonTag string,
+	mopts map[string]string,
+) (*fieldRules, error) {
+	var r fieldRules
+	found := false
+	for _, o := range []string{"min", "max", "minLength", "maxLength", "pattern", "enum"} {
+		_, ok := mopts[o]
+		found = found || ok
+	}
+	_, r.nonempty = mopts["nonempty"]
+
+	if !found && !r.nonempty {
+		return nil, nil
+	}
+
+	x := typ
+	if star, ok := x.(*ast.StarExpr); ok {
+		r.pointer = true
+		x = star.X
+	}
+
+	var t reflect.Type
+	switch x := x.(type) {
+	case *ast.Ident:
+		t = basicTypes[x.Name]
+	case *ast.ArrayType:
+		if x.Len == nil {
+			t = reflect.TypeFor[[]any]()
+		}
+	case *ast.MapType:
+		t = reflect.TypeFor[map[string]any]()
+	}
+	if t == nil {
+		if !r.pointer || found {
+			return nil, fmt.Errorf(
+				"the validation options on %s are not supported",
+				types.ExprString(typ),
+			)
+		}
+		t = reflect.TypeFor[struct{}]()
+	}
+	r.kind = t.Kind()
+	if r.pointer {
+		t = reflect.PointerTo(t)
+	}
+
+	if _, err := decodeEmpty(name, t, mocjsonTag); err != nil {
+		return nil, err
+	}
+
+	for _, o := range []struct {
+		key string
+		v   *float64
+		has *bool
+	}{
+		{"min", &r.min, &r.hasMin},
+		{"max", &r.max, &r.hasMax},
+	} {
+		s, ok := mopts[o.key]
+		if !ok {
+			continue
+		}
+		*o.v, _ = strconv.ParseFloat(s, 64)
+		if math.IsInf(*o.v, 0) || math.IsNaN(*o.v) {
+			return nil, fmt.Errorf("the %s option %s is not supported", o.key, s)
+		}
+		*o.has = true
+	}
+
+	for _, o := range []struct {
+		key string
+		v   *int
+		has *bool
+	}{
+		{"minLength", &r.minLength, &r.hasMinLength},
+		{"maxLength", &r.maxLength, &r.hasMaxLength},
+	} {
+		if s, ok := mopts[o.key]; ok {
+			*o.v, _ = strconv.Atoi(s)
+			*o.has = true
+		}
+	}
+
+	r.pattern = mopts["pattern"]
+	if s, ok := mopts["enum"]; ok {
+		r.enum = strings.Split(s, "|")
+	}
+
+	return &r, nil
+}
+
+// decodeEmpty decodes {} into a struct which has a field name of the type t
+// with the mocjson tag, and returns the field. It checks the tag as Decode
+// does.
+func decodeEmpty(name string, t reflect.Type, mocjsonTag string) (reflect.Value, error) {
+	st := reflect.StructOf([]reflect.StructField{{
+		Name: name,
+		Type: t,
+		Tag:  reflect.StructTag(`json:",omitempty" mocjson:` + strconv.Quote(mocjsonTag)),
+	}})
+	rv := reflect.New(st)
+
+	pa := mocjson.NewParser(strings.NewReader("{}"))
+	if err := pa.Decode(rv.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return rv.Elem().Field(0), nil
+}
+
+// typeOf returns the type of the expression x. The types which the generated
+// code cannot parse by itself are read with Decode.
+func (sf *sourceFile) typeOf(x ast.Expr) *goType {
+	decoded := &goType{kind: typeDecode, expr: types.ExprString(x)}
+
+	switch x := x.(type) {
+	case *ast.Ident:
+		switch x.Name {
+		case "any":
+			return &goType{kind: typeAny}
+		case "bool":
+			return &goType{kind: typeBool}
+		case "int64":
+			return &goType{kind: typeInt64}
+		case "float64":
+			return &goType{kind: typeFloat64}
+		case "string":
+			return &goType{kind: typeString}
+		}
+
+		if st, ok := sf.structs[x.Name]; ok {
+			return &goType{kind: typeStruct, st: st}
+		}
+		if u, ok := sf.unions[x.Name]; ok {
+			return &goType{kind: typeUnion, u: u}
+		}
+
+	case *ast.StarExpr:
+		elem := sf.typeOf(x.X)
+		if elem.kind != typeDecode {
+			return &goType{kind: typePointer, elem: elem}
+		}
+
+	case *ast.ArrayType:
+		if x.Len != nil {
+			break
+		}
+
+		elem := sf.typeOf(x.Elt)
+		if elem.kind != typeDecode {
+			return &goType{kind: typeSlice, elem: elem}
+		}
+
+	case *ast.InterfaceType:
+		if len(x.Methods.List) == 0 {
+			return &goType{kind: typeAny}
+		}
+	}
+
+	return decoded
+}
@@ -0,0 +1,630 @@
+package mocjson
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+var (
+	rawValueType        = reflect.TypeFor[RawValue]()
+	ratType             = reflect.TypeFor[big.Rat]()
+	unmarshalerType     = reflect.TypeFor[Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Unmarshaler is implemented by types which decode themselves from the live
+// Parser. UnmarshalMocJSON must consume exactly one value.
+type Unmarshaler interface {
+	UnmarshalMocJSON(*Parser) error
+}
+
+// Decode decodes the next value into v, which must be a non-nil pointer.
+//
+// Struct fields are matched by their json tag names. As the generated
+// Parse<Type> methods do, unknown and duplicate keys are errors, and every
+// field is required unless its tag has the omitempty option. Integer types
+// accept a number with a fraction or an exponent if its value is an integer,
+// e.g. 1.0 and 1e2.
+func (pa *Parser) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("decode target must be a non-nil pointer")
+	}
+
+	if err := pa.decodeValue(rv.Elem()); err != nil {
+		return fmt.Errorf("decode error: %w", err)
+	}
+
+	return nil
+}
+
+// DecodeContext is like Decode, but stops reading the input when ctx is done,
+// as ParseContext does.
+func (pa *Parser) DecodeContext(ctx context.Context, v any) error {
+	pa.lx.sc.ctx = ctx
+	defer func() { pa.lx.sc.ctx = nil }()
+
+	if err := pa.Decode(v); err != nil {
+		return pa.contextError(ctx, err)
+	}
+
+	return nil
+}
+
+func (pa *Parser) decodeValue(rv reflect.Value) error {
+	if rv.CanAddr() && rv.Addr().Type().Implements(unmarshalerType) {
+		return rv.Addr().Interface().(Unmarshaler).UnmarshalMocJSON(pa)
+	}
+
+	switch rv.Type() {
+	case rawValueType:
+		v, err := pa.ParseRaw()
+		if err != nil {
+			return err
+		}
+		rv.SetBytes(v)
+		return nil
+
+	case ratType:
+		v, err := pa.ParseRat()
+		if err != nil {
+			return err
+		}
+		rv.Addr().Interface().(*big.Rat).Set(v)
+		return nil
+	}
+
+	if rv.CanAddr() && rv.Addr().Type().Implements(textUnmarshalerType) {
+		v, err := pa.ParseString()
+		if err != nil {
+			return err
+		}
+		return rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(v))
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		v, err := pa.ParseBool()
+		if err != nil {
+			return err
+		}
+		rv.SetBool(v)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b, ok := pa.lx.ExpectNumberBytes()
+		if !ok {
+			return errors.New("expect int")
+		}
+		v, err := pa.lx.parseInt(b, rv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("parse int error: %w", err)
+		}
+		rv.SetInt(v)
+
+	case reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		b, ok := pa.lx.ExpectNumberBytes()
+		if !ok {
+			return errors.New("expect uint")
+		}
+		v, err := pa.lx.parseUint(b, rv.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("parse uint error: %w", err)
+		}
+		rv.SetUint(v)
+
+	case reflect.Float32:
+		b, ok := pa.lx.ExpectNumberBytes()
+		if !ok {
+			return errors.New("expect float32")
+		}
+		v, err := strconv.ParseFloat(pa.lx.floatSyntax(b), 32)
+		if err != nil {
+			return fmt.Errorf("parse float32 error: %w", err)
+		}
+		rv.SetFloat(v)
+
+	case reflect.Float64:
+		v, err := pa.ParseFloat64()
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(v)
+
+	case reflect.String:
+		v, err := pa.ParseString()
+		if err != nil {
+			return err
+		}
+		rv.SetString(v)
+
+	case reflect.Interface:
+		return pa.decodeInterface(rv)
+
+	case reflect.Pointer:
+		return pa.decodePointer(rv)
+
+	case reflect.Slice:
+		return pa.decodeSlice(rv)
+
+	case reflect.Array:
+		return pa.decodeArray(rv)
+
+	case reflect.Map:
+		return pa.decodeMap(rv)
+
+	case reflect.Struct:
+		return pa.decodeStruct(rv, "")
+
+	default:
+		return fmt.Errorf("unsupported type: %v", rv.Type())
+	}
+
+	return nil
+}
+
+func (pa *Parser) decodeInterface(rv reflect.Value) error {
+	if rv.NumMethod() != 0 {
+		u, ok := lookupUnion(rv.Type())
+		if !ok {
+			return fmt.Errorf("unsupported interface type: %v", rv.Type())
+		}
+		return pa.decodeUnion(rv, u)
+	}
+
+	v, err := pa.ParseValue()
+	if err != nil {
+		return err
+	}
+
+	if v == nil {
+		rv.SetZero()
+		return nil
+	}
+
+	rv.Set(reflect.ValueOf(v))
+	return nil
+}
+
+func (pa *Parser) decodeUnion(rv reflect.Value, u *union) error {
+	if pa.lx.NextTokenType() == TokenTypeNull {
+		if _, err := pa.ParseNull(); err != nil {
+			return err
+		}
+		rv.SetZero()
+		return nil
+	}
+
+	d, raw, err := pa.ParseDiscriminator(u.key)
+	if err != nil {
+		return err
+	}
+
+	t, ok := u.variants[d]
+	if !ok {
+		return fmt.Errorf("unknown %s %q", u.key, d)
+	}
+
+	v := reflect.New(t).Elem()
+	sub := pa.NewSubParser(raw)
+	if err := sub.decodeVariant(v, u.key); err != nil {
+		return fmt.Errorf("decode %s %q error: %w", u.key, d, err)
+	}
+	rv.Set(v)
+
+	return nil
+}
+
+// decodeVariant decodes a union variant. The discriminator key is accepted
+// even if the variant has no field for it.
+func (pa *Parser) decodeVariant(rv reflect.Value, key string) error {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return pa.decodeValue(rv)
+	}
+
+	return pa.decodeStruct(rv, key)
+}
+
+func (pa *Parser) decodePointer(rv reflect.Value) error {
+	if pa.lx.NextTokenType() == TokenTypeNull {
+		if _, err := pa.ParseNull(); err != nil {
+			return err
+		}
+		rv.SetZero()
+		return nil
+	}
+
+	if rv.IsNil() {
+		rv.Set(reflect.New(rv.Type().Elem()))
+	}
+
+	return pa.decodeValue(rv.Elem())
+}
+
+func (pa *Parser) decodeSlice(rv reflect.Value) error {
+	if pa.lx.NextTokenType() == TokenTypeNull {
+		if _, err := pa.ParseNull(); err != nil {
+			return err
+		}
+		rv.SetZero()
+		return nil
+	}
+
+	if !pa.lx.ExpectBeginArray() {
+		return errors.New("expect begin array")
+	}
+
+	ret := reflect.MakeSlice(rv.Type(), 0, 0)
+
+	// empty array
+	if pa.lx.NextTokenType() == TokenTypeEndArray {
+		pa.lx.sc.Skip(1)
+		rv.Set(ret)
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		ret = reflect.Append(ret, reflect.New(rv.Type().Elem()).Elem())
+		if err := pa.decodeValue(ret.Index(i)); err != nil {
+			return fmt.Errorf("decode [%d] error: %w", i, PrependPath(err, IndexPath(i)))
+		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndArray:
+			pa.lx.sc.Skip(1)
+			rv.Set(ret)
+			return nil
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndArray) {
+				pa.lx.sc.Skip(1)
+				rv.Set(ret)
+				return nil
+			}
+
+		default:
+			return errors.New("expect value separator or end array")
+		}
+	}
+}
+
+func (pa *Parser) decodeArray(rv reflect.Value) error {
+	if !pa.lx.ExpectBeginArray() {
+		return errors.New("expect begin array")
+	}
+
+	// empty array
+	if pa.lx.NextTokenType() == TokenTypeEndArray {
+		pa.lx.sc.Skip(1)
+		if rv.Len() != 0 {
+			return errors.New("array length mismatch")
+		}
+		return nil
+	}
+
+	for i := 0; ; i++ {
+		if i >= rv.Len() {
+			return errors.New("array length mismatch")
+		}
+
+		if err := pa.decodeValue(rv.Index(i)); err != nil {
+			return fmt.Errorf("decode [%d] error: %w", i, PrependPath(err, IndexPath(i)))
+		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndArray:
+			pa.lx.sc.Skip(1)
+			if i+1 != rv.Len() {
+				return errors.New("array length mismatch")
+			}
+			return nil
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndArray) {
+				pa.lx.sc.Skip(1)
+				if i+1 != rv.Len() {
+					return errors.New("array length mismatch")
+				}
+				return nil
+			}
+
+		default:
+			return errors.New("expect value separator or end array")
+		}
+	}
+}
+
+func (pa *Parser) decodeMap(rv reflect.Value) error {
+	if !isDecodableMapKey(rv.Type().Key()) {
+		return fmt.Errorf("unsupported map key type: %v", rv.Type().Key())
+	}
+
+	if pa.lx.NextTokenType() == TokenTypeNull {
+		if _, err := pa.ParseNull(); err != nil {
+			return err
+		}
+		rv.SetZero()
+		return nil
+	}
+
+	if !pa.lx.ExpectBeginObject() {
+		return errors.New("expect begin object")
+	}
+
+	ret := reflect.MakeMap(rv.Type())
+
+	// empty object
+	if pa.lx.NextTokenType() == TokenTypeEndObject {
+		pa.lx.sc.Skip(1)
+		rv.Set(ret)
+		return nil
+	}
+
+	for {
+		k, err := pa.parseKey()
+		if err != nil {
+			return fmt.Errorf("parse key error: %w", err)
+		}
+
+		if !pa.lx.ExpectNameSeparator() {
+			return errors.New("expect name separator")
+		}
+
+		kv, err := decodeMapKey(k, rv.Type().Key())
+		if err != nil {
+			return fmt.Errorf("decode key %q error: %w", k, err)
+		}
+
+		v := reflect.New(rv.Type().Elem()).Elem()
+		if err := pa.decodeValue(v); err != nil {
+			return fmt.Errorf("decode %s error: %w", k, PrependPath(err, KeyPath(k)))
+		}
+		ret.SetMapIndex(kv, v)
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndObject:
+			pa.lx.sc.Skip(1)
+			rv.Set(ret)
+			return nil
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndObject) {
+				pa.lx.sc.Skip(1)
+				rv.Set(ret)
+				return nil
+			}
+
+		default:
+			return errors.New("expect value separator or end object")
+		}
+	}
+}
+
+// decodeDefaults decodes the default values into the fields which are not
+// seen, and then validates that the required fields are seen.
+func decodeDefaults(rv reflect.Value, si *structInfo, seen []bool) error {
+	for i := range si.fields {
+		f := &si.fields[i]
+		if seen[i] || f.defaultValue == nil {
+			continue
+		}
+
+		fv, err := fieldByIndexAlloc(rv, f.index)
+		if err != nil {
+			return err
+		}
+
+		pa := NewParser(bytes.NewReader(f.defaultValue))
+		if err := pa.decodeField(fv, f); err != nil {
+			return fmt.Errorf(
+				"decode default %s error: %w",
+				f.name,
+				PrependPath(err, KeyPath(f.name)),
+			)
+		}
+	}
+
+	return si.validate(seen)
+}
+
+// decodeField decodes the field value with the tag options, and then checks
+// the validation options.
+func (pa *Parser) decodeField(rv reflect.Value, f *fieldInfo) error {
+	if err := pa.decodeFieldValue(rv, f); err != nil {
+		return err
+	}
+
+	if f.rules != nil {
+		return f.rules.check(rv)
+	}
+
+	return nil
+}
+
+func (pa *Parser) decodeFieldValue(rv reflect.Value, f *fieldInfo) error {
+	if f.timeLayout == "" && !f.duration {
+		return pa.decodeValue(rv)
+	}
+
+	for rv.Kind() == reflect.Pointer {
+		if pa.lx.NextTokenType() == TokenTypeNull {
+			if _, err := pa.ParseNull(); err != nil {
+				return err
+			}
+			rv.SetZero()
+			return nil
+		}
+
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	if f.duration {
+		v, err := pa.ParseDuration()
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(v))
+		return nil
+	}
+
+	v, err := pa.ParseTime(f.timeLayout)
+	if err != nil {
+		return err
+	}
+	rv.Set(reflect.ValueOf(v))
+	return nil
+}
+
+func isDecodableMapKey(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64,
+		reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		return true
+	}
+
+	return false
+}
+
+func decodeMapKey(k string, t reflect.Type) (reflect.Value, error) {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		kv := reflect.New(t)
+		if err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+			return reflect.Value{}, err
+		}
+		return kv.Elem(), nil
+	}
+
+	kv := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(k, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("parse int error: %w", err)
+		}
+		kv.SetInt(n)
+
+	case reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		n, err := strconv.ParseUint(k, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("parse uint error: %w", err)
+		}
+		kv.SetUint(n)
+
+	default:
+		kv.SetString(k)
+	}
+
+	return kv, nil
+}
+
+func (pa *Parser) decodeStruct(rv reflect.Value, ignoreKey string) error {
+	si, err := cachedStructInfo(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	if !pa.lx.ExpectBeginObject() {
+		return errors.New("expect begin object")
+	}
+
+	seen := make([]bool, len(si.fields))
+
+	// empty object
+	if pa.lx.NextTokenType() == TokenTypeEndObject {
+		pa.lx.sc.Skip(1)
+		return decodeDefaults(rv, si, seen)
+	}
+
+	for {
+		k, err := pa.parseKey()
+		if err != nil {
+			return fmt.Errorf("parse key error: %w", err)
+		}
+
+		i, ok := si.lookup(k)
+		if !ok && k != ignoreKey {
+			return fmt.Errorf("unknown key %q", k)
+		}
+		if ok && seen[i] {
+			return fmt.Errorf("duplicate key %q", k)
+		}
+
+		if !pa.lx.ExpectNameSeparator() {
+			return errors.New("expect name separator")
+		}
+
+		if ok {
+			seen[i] = true
+
+			f := &si.fields[i]
+			fv, err := fieldByIndexAlloc(rv, f.index)
+			if err != nil {
+				return err
+			}
+			if err := pa.decodeField(fv, f); err != nil {
+				return fmt.Errorf("decode %s error: %w", f.name, PrependPath(err, KeyPath(f.name)))
+			}
+		} else if err := pa.skipValue(); err != nil {
+			return fmt.Errorf("skip %s error: %w", k, err)
+		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndObject:
+			pa.lx.sc.Skip(1)
+			return decodeDefaults(rv, si, seen)
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndObject) {
+				pa.lx.sc.Skip(1)
+				return decodeDefaults(rv, si, seen)
+			}
+
+		default:
+			return errors.New("expect value separator or end object")
+		}
+	}
+}
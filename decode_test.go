@@ -0,0 +1,1122 @@
+package mocjson
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type decodeTestObject struct {
+	Int      int            `json:"int"`
+	String   string         `json:"string"`
+	Pointer  *float64       `json:"pointer"`
+	Slice    []uint8        `json:"slice"`
+	Map      map[string]any `json:"map,omitempty"`
+	Raw      RawValue       `json:"raw,omitempty"`
+	Ignored  string         `json:"-"`
+	Untagged bool
+	private  bool
+}
+
+type decodeTestEventHolder struct {
+	Event SampleEvent `json:"event"`
+}
+
+type decodeTestKey string
+
+type decodeTestInner struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type decodeTestOther struct {
+	Name string `json:"name"`
+	Tag  string
+}
+
+type decodeTestTagged struct {
+	Tag string `json:"Tag"`
+}
+
+type decodeTestEmbedded struct {
+	decodeTestInner
+	*decodeTestOther
+	decodeTestTagged
+	ID int `json:"id"`
+}
+
+type decodeTestPointerEmbedded struct {
+	*SampleBase
+	Extra string `json:"extra,omitempty"`
+}
+
+type decodeTestUnexportedPointerEmbedded struct {
+	*decodeTestInner
+}
+
+type decodeTestNamedEmbedded struct {
+	decodeTestInner `json:"inner"`
+}
+
+type decodeTestAliased struct {
+	UserID string `json:"userId"         mocjson:"alias=user_id|uid"`
+	Email  string `json:"email"          mocjson:"nocase,alias=mail"`
+	Note   string `json:"note,omitempty"`
+}
+
+type decodeTestDefault struct {
+	Port    int               `json:"port"             mocjson:"default=10"`
+	User    string            `json:"user"             mocjson:"default=\"guest\""`
+	Tags    []string          `json:"tags"             mocjson:"default=[\"a\", \"b\"]"`
+	Limits  map[string]int    `json:"limits"           mocjson:"default={\"cpu\":1,\"mem\":2}"`
+	Labels  map[string]string `json:"labels" mocjson:"default={\"a\":\"1\",\"b\":\"x=y\"},nonempty"`
+	Timeout time.Duration     `json:"timeout,duration" mocjson:"default=\"1m\""`
+	Name    string            `json:"name"`
+}
+
+type decodeTestEnvelope struct {
+	Type    string   `json:"type"`
+	Payload RawValue `json:"payload"`
+}
+
+func TestParser_Decode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       []byte
+		newV    func() any
+		want    any
+		wantErr bool
+	}{
+		{
+			name: "bool",
+			b:    []byte("true"),
+			newV: func() any { return new(bool) },
+			want: true,
+		},
+		{
+			name: "int",
+			b:    []byte("-123"),
+			newV: func() any { return new(int) },
+			want: -123,
+		},
+		{
+			name:    "int8 overflow",
+			b:       []byte("128"),
+			newV:    func() any { return new(int8) },
+			want:    int8(0),
+			wantErr: true,
+		},
+		{
+			name:    "int with frac",
+			b:       []byte("1.5"),
+			newV:    func() any { return new(int) },
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "int with zero frac",
+			b:    []byte("-100.0"),
+			newV: func() any { return new(int) },
+			want: -100,
+		},
+		{
+			name: "int with exp",
+			b:    []byte("1.5E1"),
+			newV: func() any { return new(int) },
+			want: 15,
+		},
+		{
+			name: "int with negative exp",
+			b:    []byte("1500e-2"),
+			newV: func() any { return new(int) },
+			want: 15,
+		},
+		{
+			name: "int zero with huge exp",
+			b:    []byte("0.0e-99999999999999999999"),
+			newV: func() any { return new(int) },
+			want: 0,
+		},
+		{
+			name:    "int with non-integer exp",
+			b:       []byte("15e-1"),
+			newV:    func() any { return new(int) },
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:    "int with huge exp",
+			b:       []byte("1e99999999999999999999"),
+			newV:    func() any { return new(int) },
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:    "int8 overflow with exp",
+			b:       []byte("1.28e2"),
+			newV:    func() any { return new(int8) },
+			want:    int8(0),
+			wantErr: true,
+		},
+		{
+			name: "uint64 with exp",
+			b:    []byte("1.8446744073709551615e19"),
+			newV: func() any { return new(uint64) },
+			want: uint64(18446744073709551615),
+		},
+		{
+			name:    "uint64 overflow with exp",
+			b:       []byte("1.8446744073709551616e19"),
+			newV:    func() any { return new(uint64) },
+			want:    uint64(0),
+			wantErr: true,
+		},
+		{
+			name: "uint64",
+			b:    []byte("18446744073709551615"),
+			newV: func() any { return new(uint64) },
+			want: uint64(18446744073709551615),
+		},
+		{
+			name:    "negative uint",
+			b:       []byte("-1"),
+			newV:    func() any { return new(uint) },
+			want:    uint(0),
+			wantErr: true,
+		},
+		{
+			name: "float32",
+			b:    []byte("1.5"),
+			newV: func() any { return new(float32) },
+			want: float32(1.5),
+		},
+		{
+			name: "string",
+			b:    []byte(`"hello"`),
+			newV: func() any { return new(string) },
+			want: "hello",
+		},
+		{
+			name: "any",
+			b:    []byte(`{"key":[1,"value",null]}`),
+			newV: func() any { return new(any) },
+			want: any(map[string]any{"key": []any{1.0, "value", nil}}),
+		},
+		{
+			name: "null pointer",
+			b:    []byte("null"),
+			newV: func() any { return new(*int) },
+			want: (*int)(nil),
+		},
+		{
+			name: "slice",
+			b:    []byte("[1,2,3]"),
+			newV: func() any { return new([]int) },
+			want: []int{1, 2, 3},
+		},
+		{
+			name: "empty slice",
+			b:    []byte("[]"),
+			newV: func() any { return new([]int) },
+			want: []int{},
+		},
+		{
+			name: "array",
+			b:    []byte("[1,2,3]"),
+			newV: func() any { return new([3]int) },
+			want: [3]int{1, 2, 3},
+		},
+		{
+			name:    "array length mismatch",
+			b:       []byte("[1,2]"),
+			newV:    func() any { return new([3]int) },
+			want:    [3]int{1, 2, 0},
+			wantErr: true,
+		},
+		{
+			name: "map",
+			b:    []byte(`{"key1":1,"key2":2}`),
+			newV: func() any { return new(map[string]int) },
+			want: map[string]int{"key1": 1, "key2": 2},
+		},
+		{
+			name: "rat",
+			b:    []byte("0.1"),
+			newV: func() any { return new(big.Rat) },
+			want: *big.NewRat(1, 10),
+		},
+		{
+			name: "raw",
+			b:    []byte(` [1, {"key": "value"}] `),
+			newV: func() any { return new(RawValue) },
+			want: RawValue(`[1, {"key": "value"}]`),
+		},
+		{
+			name: "struct",
+			b: []byte(
+				`{"int":1,"string":"hello","pointer":1.5,"slice":[1,2],"map":{"key":"value"},"raw":[true],"Untagged":true}`,
+			),
+			newV: func() any { return new(decodeTestObject) },
+			want: decodeTestObject{
+				Int:      1,
+				String:   "hello",
+				Pointer:  func() *float64 { f := 1.5; return &f }(),
+				Slice:    []uint8{1, 2},
+				Map:      map[string]any{"key": "value"},
+				Raw:      RawValue("[true]"),
+				Untagged: true,
+			},
+		},
+		{
+			name: "struct without optional fields",
+			b:    []byte(`{"int":1,"string":"hello","pointer":null,"slice":null,"Untagged":false}`),
+			newV: func() any { return new(decodeTestObject) },
+			want: decodeTestObject{Int: 1, String: "hello"},
+		},
+		{
+			name: "struct missing required field",
+			b:    []byte(`{"int":1,"pointer":null,"slice":null,"Untagged":false}`),
+			newV: func() any { return new(decodeTestObject) },
+			want: decodeTestObject{
+				Int: 1,
+			},
+			wantErr: true,
+		},
+		{
+			name:    "struct unknown key",
+			b:       []byte(`{"unknown":1}`),
+			newV:    func() any { return new(decodeTestObject) },
+			want:    decodeTestObject{},
+			wantErr: true,
+		},
+		{
+			name:    "struct ignored key",
+			b:       []byte(`{"Ignored":"value"}`),
+			newV:    func() any { return new(decodeTestObject) },
+			want:    decodeTestObject{},
+			wantErr: true,
+		},
+		{
+			name:    "struct duplicate key",
+			b:       []byte(`{"int":1,"int":2}`),
+			newV:    func() any { return new(decodeTestObject) },
+			want:    decodeTestObject{Int: 1},
+			wantErr: true,
+		},
+		{
+			name: "envelope",
+			b:    []byte(`{"payload": {"key": [1, 2]}, "type": "sample"}`),
+			newV: func() any { return new(decodeTestEnvelope) },
+			want: decodeTestEnvelope{
+				Type:    "sample",
+				Payload: RawValue(`{"key": [1, 2]}`),
+			},
+		},
+		{
+			name: "embedded",
+			b:    []byte(`{"id":1,"Tag":"tag","comment":"hello"}`),
+			newV: func() any { return new(decodeTestEmbedded) },
+			want: decodeTestEmbedded{
+				decodeTestInner:  decodeTestInner{Comment: "hello"},
+				decodeTestTagged: decodeTestTagged{Tag: "tag"},
+				ID:               1,
+			},
+		},
+		{
+			name:    "embedded conflict is dropped",
+			b:       []byte(`{"id":1,"Tag":"tag","name":"hello"}`),
+			newV:    func() any { return new(decodeTestEmbedded) },
+			want:    decodeTestEmbedded{decodeTestTagged: decodeTestTagged{Tag: "tag"}, ID: 1},
+			wantErr: true,
+		},
+		{
+			name: "sample base",
+			b: []byte(
+				`{"boolean":true,"float64":1,"string":"s","object":{},"array":[],"any":null}`,
+			),
+			newV: func() any { return new(SampleObject2) },
+			want: SampleObject2{
+				SampleBase: SampleBase{
+					Boolean: true,
+					Float64: 1,
+					String:  "s",
+					Object:  map[string]any{},
+					Array:   []any{},
+				},
+			},
+		},
+		{
+			name: "embedded pointer",
+			b: []byte(
+				`{"boolean":true,"float64":1,"string":"s","object":null,"array":null}`,
+			),
+			newV: func() any { return new(decodeTestPointerEmbedded) },
+			want: decodeTestPointerEmbedded{
+				SampleBase: &SampleBase{Boolean: true, Float64: 1, String: "s"},
+			},
+		},
+		{
+			name:    "unexported embedded pointer",
+			b:       []byte(`{"id":1,"name":"hello"}`),
+			newV:    func() any { return new(decodeTestUnexportedPointerEmbedded) },
+			want:    decodeTestUnexportedPointerEmbedded{},
+			wantErr: true,
+		},
+		{
+			name: "alias",
+			b:    []byte(`{"uid":"u","MAIL":"m"}`),
+			newV: func() any { return new(decodeTestAliased) },
+			want: decodeTestAliased{UserID: "u", Email: "m"},
+		},
+		{
+			name: "nocase",
+			b:    []byte(`{"user_id":"u","Email":"m","note":"n"}`),
+			newV: func() any { return new(decodeTestAliased) },
+			want: decodeTestAliased{UserID: "u", Email: "m", Note: "n"},
+		},
+		{
+			name:    "alias is case-sensitive without nocase",
+			b:       []byte(`{"UID":"u","email":"m"}`),
+			newV:    func() any { return new(decodeTestAliased) },
+			want:    decodeTestAliased{},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate key by alias",
+			b:       []byte(`{"userId":"u","uid":"v","email":"m"}`),
+			newV:    func() any { return new(decodeTestAliased) },
+			want:    decodeTestAliased{UserID: "u"},
+			wantErr: true,
+		},
+		{
+			name: "default",
+			b:    []byte(`{"name":"n","user":"root"}`),
+			newV: func() any { return new(decodeTestDefault) },
+			want: decodeTestDefault{
+				Port:    10,
+				User:    "root",
+				Tags:    []string{"a", "b"},
+				Limits:  map[string]int{"cpu": 1, "mem": 2},
+				Labels:  map[string]string{"a": "1", "b": "x=y"},
+				Timeout: time.Minute,
+				Name:    "n",
+			},
+		},
+		{
+			name: "default does not make other fields optional",
+			b:    []byte(`{}`),
+			newV: func() any { return new(decodeTestDefault) },
+			want: decodeTestDefault{
+				Port:    10,
+				User:    "guest",
+				Tags:    []string{"a", "b"},
+				Limits:  map[string]int{"cpu": 1, "mem": 2},
+				Labels:  map[string]string{"a": "1", "b": "x=y"},
+				Timeout: time.Minute,
+			},
+			wantErr: true,
+		},
+		{
+			name: "default of wrong type",
+			b:    []byte(`{}`),
+			newV: func() any {
+				return new(struct {
+					N int `json:"n" mocjson:"default=\"10\""`
+				})
+			},
+			want: struct {
+				N int `json:"n" mocjson:"default=\"10\""`
+			}{},
+			wantErr: true,
+		},
+		{
+			name: "named embedded",
+			b:    []byte(`{"inner":{"id":1,"name":"hello"}}`),
+			newV: func() any { return new(decodeTestNamedEmbedded) },
+			want: decodeTestNamedEmbedded{decodeTestInner{ID: 1, Name: "hello"}},
+		},
+		{
+			name: "union",
+			b:    []byte(`{"kind":"created","id":"1","name":"hello"}`),
+			newV: func() any { return new(SampleEvent) },
+			want: SampleEvent(SampleCreatedEvent{ID: "1", Name: "hello"}),
+		},
+		{
+			name: "union discriminator not first",
+			b:    []byte(`{"event":{"id":"1","kind":"deleted"}}`),
+			newV: func() any { return new(decodeTestEventHolder) },
+			want: decodeTestEventHolder{Event: SampleDeletedEvent{ID: "1", Reason: "unknown"}},
+		},
+		{
+			name: "union null",
+			b:    []byte(`{"event":null}`),
+			newV: func() any { return new(decodeTestEventHolder) },
+			want: decodeTestEventHolder{},
+		},
+		{
+			name:    "union unknown discriminator",
+			b:       []byte(`{"kind":"updated","id":"1"}`),
+			newV:    func() any { return new(SampleEvent) },
+			want:    SampleEvent(nil),
+			wantErr: true,
+		},
+		{
+			name:    "union missing discriminator",
+			b:       []byte(`{"id":"1"}`),
+			newV:    func() any { return new(SampleEvent) },
+			want:    SampleEvent(nil),
+			wantErr: true,
+		},
+		{
+			name:    "union invalid variant",
+			b:       []byte(`{"kind":"deleted","id":"1","name":"hello"}`),
+			newV:    func() any { return new(SampleEvent) },
+			want:    SampleEvent(nil),
+			wantErr: true,
+		},
+		{
+			name:    "unregistered interface",
+			b:       []byte(`"hello"`),
+			newV:    func() any { return new(fmt.Stringer) },
+			want:    fmt.Stringer(nil),
+			wantErr: true,
+		},
+		{
+			name: "unmarshaler",
+			b:    []byte(`[1, 2.5]`),
+			newV: func() any { return new(testPoint) },
+			want: testPoint{X: 1, Y: 2.5},
+		},
+		{
+			name: "unmarshaler pointer",
+			b:    []byte(`{"key":[1,2]}`),
+			newV: func() any { return new(map[string]*testPoint) },
+			want: map[string]*testPoint{"key": {X: 1, Y: 2}},
+		},
+		{
+			name:    "unmarshaler error",
+			b:       []byte(`[1]`),
+			newV:    func() any { return new(testPoint) },
+			want:    testPoint{},
+			wantErr: true,
+		},
+		{
+			name: "int64 map key",
+			b:    []byte(`{"-1":"a","9223372036854775807":"b"}`),
+			newV: func() any { return new(map[int64]string) },
+			want: map[int64]string{-1: "a", 9223372036854775807: "b"},
+		},
+		{
+			name: "uint32 map key",
+			b:    []byte(`{"0":"a","4294967295":"b"}`),
+			newV: func() any { return new(map[uint32]string) },
+			want: map[uint32]string{0: "a", 4294967295: "b"},
+		},
+		{
+			name:    "uint32 map key overflow",
+			b:       []byte(`{"4294967296":"a"}`),
+			newV:    func() any { return new(map[uint32]string) },
+			want:    map[uint32]string(nil),
+			wantErr: true,
+		},
+		{
+			name:    "int map key not a number",
+			b:       []byte(`{"one":"a"}`),
+			newV:    func() any { return new(map[int]string) },
+			want:    map[int]string(nil),
+			wantErr: true,
+		},
+		{
+			name: "named string map key",
+			b:    []byte(`{"key":{"1":true}}`),
+			newV: func() any { return new(map[decodeTestKey]map[int8]bool) },
+			want: map[decodeTestKey]map[int8]bool{"key": {1: true}},
+		},
+		{
+			name:    "unsupported map key",
+			b:       []byte(`{"1.5":true}`),
+			newV:    func() any { return new(map[float64]bool) },
+			want:    map[float64]bool(nil),
+			wantErr: true,
+		},
+		{
+			name: "text unmarshaler",
+			b:    []byte(`"192.0.2.1"`),
+			newV: func() any { return new(netip.Addr) },
+			want: netip.MustParseAddr("192.0.2.1"),
+		},
+		{
+			name: "text unmarshaler time",
+			b:    []byte(`["2006-01-02T15:04:05Z",null]`),
+			newV: func() any { return new([]*time.Time) },
+			want: []*time.Time{
+				func() *time.Time { t := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC); return &t }(),
+				nil,
+			},
+		},
+		{
+			name:    "text unmarshaler error",
+			b:       []byte(`"192.0.2"`),
+			newV:    func() any { return new(netip.Addr) },
+			want:    netip.Addr{},
+			wantErr: true,
+		},
+		{
+			name:    "text unmarshaler not a string",
+			b:       []byte(`1`),
+			newV:    func() any { return new(netip.Addr) },
+			want:    netip.Addr{},
+			wantErr: true,
+		},
+		{
+			name: "text unmarshaler map key",
+			b:    []byte(`{"192.0.2.1":1,"2001:db8::1":2}`),
+			newV: func() any { return new(map[netip.Addr]int) },
+			want: map[netip.Addr]int{
+				netip.MustParseAddr("192.0.2.1"):   1,
+				netip.MustParseAddr("2001:db8::1"): 2,
+			},
+		},
+		{
+			name:    "text unmarshaler map key error",
+			b:       []byte(`{"192.0.2":1}`),
+			newV:    func() any { return new(map[netip.Addr]int) },
+			want:    map[netip.Addr]int(nil),
+			wantErr: true,
+		},
+		{
+			name:    "type mismatch",
+			b:       []byte(`"hello"`),
+			newV:    func() any { return new(int) },
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bytes.NewReader(tt.b)
+			pa := NewParser(r)
+
+			v := tt.newV()
+			err := pa.Decode(v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := reflect.ValueOf(v).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_DecodeContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := `[` + strings.Repeat(`{"int":1},`, 100000) + `{"int":1}]`
+	r := &cancelReader{r: strings.NewReader(b), n: 3, cancel: cancel}
+	pa := NewParser(r)
+
+	var v []struct {
+		Int int `json:"int"`
+	}
+	if err := pa.DecodeContext(ctx, &v); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if r.reads != 3 {
+		t.Errorf("got %d reads, want 3", r.reads)
+	}
+
+	pa = NewParser(strings.NewReader(`[{"int":1}]`))
+	if err := pa.DecodeContext(context.Background(), &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v) != 1 || v[0].Int != 1 {
+		t.Errorf("got %v", v)
+	}
+}
+
+func TestParser_DecodeJSON5(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Int    int            `json:"int"`
+		Uint   uint8          `json:"uint"`
+		Float  float32        `json:"float"`
+		Slice  []int          `json:"slice"`
+		Array  [2]string      `json:"array"`
+		Map    map[string]int `json:"map"`
+		Events []SampleEvent  `json:"events"`
+	}
+
+	b := `// config
+{
+  int: -0x10,
+  uint: +0xff,
+  float: 0x8, /* hex */
+  slice: [1, 2,],
+  array: ['a', 'b',],
+  map: {x: 1,},
+  events: [{id: '1', kind: 'deleted', reason: 'expired',},],
+}`
+
+	pa := NewParserWithOptions(strings.NewReader(b), ParserOptions{JSON5: true})
+
+	var got config
+	if err := pa.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := config{
+		Int:    -16,
+		Uint:   255,
+		Float:  8,
+		Slice:  []int{1, 2},
+		Array:  [2]string{"a", "b"},
+		Map:    map[string]int{"x": 1},
+		Events: []SampleEvent{SampleDeletedEvent{ID: "1", Reason: "expired"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	strict := NewParser(strings.NewReader(`{"int": 0x10}`))
+	if err := strict.Decode(&got); err == nil {
+		t.Error("want an error in strict mode")
+	}
+}
+
+func TestParser_Decode_InvalidTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{
+			name: "nil",
+			v:    nil,
+		},
+		{
+			name: "non-pointer",
+			v:    0,
+		},
+		{
+			name: "nil pointer",
+			v:    (*int)(nil),
+		},
+		{
+			name: "alias conflicts",
+			v: &struct {
+				A int `json:"a" mocjson:"alias=b"`
+				B int `json:"b"`
+			}{},
+		},
+		{
+			name: "min option on string",
+			v: &struct {
+				A string `json:"a" mocjson:"min=1"`
+			}{},
+		},
+		{
+			name: "invalid pattern",
+			v: &struct {
+				A string `json:"a" mocjson:"pattern=("`
+			}{},
+		},
+		{
+			name: "invalid maxLength",
+			v: &struct {
+				A string `json:"a" mocjson:"maxLength=1.5"`
+			}{},
+		},
+		{
+			name: "invalid default",
+			v: &struct {
+				A int `json:"a" mocjson:"default=1 2"`
+			}{},
+		},
+		{
+			name: "empty alias",
+			v: &struct {
+				A int `json:"a" mocjson:"alias="`
+			}{},
+		},
+		{
+			name: "empty alias in list",
+			v: &struct {
+				A int `json:"a" mocjson:"alias=b||c"`
+			}{},
+		},
+		{
+			name: "unknown option",
+			v: &struct {
+				A string `json:"a" mocjson:"alias=b,c"`
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParser(bytes.NewReader([]byte("0")))
+
+			if err := pa.Decode(tt.v); err == nil {
+				t.Errorf("got nil, want error")
+			}
+		})
+	}
+}
+
+func TestParseMocJSONTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tag     string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			tag:  "",
+			want: map[string]string{},
+		},
+		{
+			name: "alias before nocase",
+			tag:  "alias=user_id|uid,nocase",
+			want: map[string]string{"alias": "user_id|uid", "nocase": ""},
+		},
+		{
+			name: "alias after nocase",
+			tag:  "nocase,alias=user_id|uid",
+			want: map[string]string{"alias": "user_id|uid", "nocase": ""},
+		},
+		{
+			name: "enum before nonempty",
+			tag:  "enum=a|b,nonempty",
+			want: map[string]string{"enum": "a|b", "nonempty": ""},
+		},
+		{
+			name: "enum after nonempty",
+			tag:  "nonempty,enum=a|b",
+			want: map[string]string{"enum": "a|b", "nonempty": ""},
+		},
+		{
+			name: "pattern takes the rest",
+			tag:  "minLength=1,pattern=^a{1,2},b=c$",
+			want: map[string]string{"minLength": "1", "pattern": "^a{1,2},b=c$"},
+		},
+		{
+			name: "default at last",
+			tag:  `enum=a|b,default="a,b=c"`,
+			want: map[string]string{"enum": "a|b", "default": `"a,b=c"`},
+		},
+		{
+			name: "default followed by options",
+			tag:  `default={"a":1,"b":"x=y"},nonempty`,
+			want: map[string]string{"default": `{"a":1,"b":"x=y"}`, "nonempty": ""},
+		},
+		{
+			name: "default and pattern",
+			tag:  `default="ab",pattern=^a,b$`,
+			want: map[string]string{"default": `"ab"`, "pattern": "^a,b$"},
+		},
+		{
+			name:    "default with garbage",
+			tag:     "default=1 2,nonempty",
+			wantErr: true,
+		},
+		{
+			name:    "empty default",
+			tag:     "default=,nonempty",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated default",
+			tag:     `default={"a":1`,
+			wantErr: true,
+		},
+		{
+			name:    "values separated by commas",
+			tag:     "alias=user_id,uid",
+			wantErr: true,
+		},
+		{
+			name:    "unknown option",
+			tag:     "nocase,unique",
+			wantErr: true,
+		},
+		{
+			name:    "empty option",
+			tag:     "nocase,,nonempty",
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			tag:     "min",
+			wantErr: true,
+		},
+		{
+			name:    "unexpected value",
+			tag:     "nocase=true",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate option",
+			tag:     "min=1,min=2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseMocJSONTag(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type validateTestItem struct {
+	Name  string  `json:"name"  mocjson:"minLength=1,maxLength=3"`
+	Score float64 `json:"score" mocjson:"min=0,max=1"`
+}
+
+type validateTestObject struct {
+	Count  uint                        `json:"count"  mocjson:"max=10"`
+	Code   string                      `json:"code"   mocjson:"pattern=^[A-Z]{2,3}$"`
+	Color  string                      `json:"color"  mocjson:"enum=red|green|blue"`
+	Level  int                         `json:"level"  mocjson:"enum=1|2|3"`
+	Tags   []string                    `json:"tags"   mocjson:"nonempty,maxLength=2"`
+	Owner  *string                     `json:"owner"  mocjson:"nonempty"`
+	Items  []validateTestItem          `json:"items"`
+	Labels map[string]validateTestItem `json:"labels,omitempty"`
+	Mode   string                      `json:"mode,omitempty" mocjson:"enum=a|b,default=\"c\""`
+}
+
+func TestParser_Decode_Validation(t *testing.T) {
+	t.Parallel()
+
+	const valid = `{"count":10,"code":"ABC","color":"red","level":2,"tags":["a"],"owner":"o","items":[{"name":"n","score":0.5}],"mode":"a"}`
+
+	with := func(old, new string) []byte {
+		return []byte(strings.Replace(valid, old, new, 1))
+	}
+
+	tests := []struct {
+		name     string
+		b        []byte
+		wantPath string
+		wantRule string
+	}{
+		{
+			name: "ok",
+			b:    []byte(valid),
+		},
+		{
+			name:     "max",
+			b:        with(`"count":10`, `"count":11`),
+			wantPath: ".count",
+			wantRule: "max",
+		},
+		{
+			name:     "pattern",
+			b:        with(`"code":"ABC"`, `"code":"ABCD"`),
+			wantPath: ".code",
+			wantRule: "pattern",
+		},
+		{
+			name:     "enum",
+			b:        with(`"color":"red"`, `"color":"black"`),
+			wantPath: ".color",
+			wantRule: "enum",
+		},
+		{
+			name:     "integer enum",
+			b:        with(`"level":2`, `"level":4`),
+			wantPath: ".level",
+			wantRule: "enum",
+		},
+		{
+			name:     "nonempty slice",
+			b:        with(`"tags":["a"]`, `"tags":[]`),
+			wantPath: ".tags",
+			wantRule: "nonempty",
+		},
+		{
+			name:     "maxLength slice",
+			b:        with(`"tags":["a"]`, `"tags":["a","b","c"]`),
+			wantPath: ".tags",
+			wantRule: "maxLength",
+		},
+		{
+			name:     "nonempty pointer",
+			b:        with(`"owner":"o"`, `"owner":null`),
+			wantPath: ".owner",
+			wantRule: "nonempty",
+		},
+		{
+			name: "nested minLength",
+			b: with(
+				`{"name":"n","score":0.5}`,
+				`{"name":"n","score":0},{"name":"","score":0}`,
+			),
+			wantPath: ".items[1].name",
+			wantRule: "minLength",
+		},
+		{
+			name:     "nested maxLength counts runes",
+			b:        with(`"name":"n"`, `"name":"🍣🍣🍣🍣"`),
+			wantPath: ".items[0].name",
+			wantRule: "maxLength",
+		},
+		{
+			name:     "nested min",
+			b:        with(`,"mode":"a"`, `,"mode":"a","labels":{"a b":{"name":"n","score":-1}}`),
+			wantPath: `.labels["a b"].score`,
+			wantRule: "min",
+		},
+		{
+			name:     "default",
+			b:        with(`,"mode":"a"`, ``),
+			wantPath: ".mode",
+			wantRule: "enum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParser(bytes.NewReader(tt.b))
+
+			var v validateTestObject
+			err := pa.Decode(&v)
+			if tt.wantRule == "" {
+				if err != nil {
+					t.Errorf("got %v, want nil", err)
+				}
+				return
+			}
+
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("got %v, want ValidationError", err)
+			}
+			if ve.Path != tt.wantPath {
+				t.Errorf("got path %s, want %s", ve.Path, tt.wantPath)
+			}
+			if ve.Rule != tt.wantRule {
+				t.Errorf("got rule %s, want %s", ve.Rule, tt.wantRule)
+			}
+		})
+	}
+}
+
+type registerTestEvent interface {
+	registerTestEvent()
+}
+
+type registerTestEventA struct{}
+
+func (registerTestEventA) registerTestEvent() {}
+
+type registerTestEventB struct{}
+
+func (registerTestEventB) registerTestEvent() {}
+
+func TestRegisterVariant(t *testing.T) {
+	t.Parallel()
+
+	RegisterVariant[registerTestEvent, registerTestEventA]("type", "a")
+
+	tests := []struct {
+		name     string
+		register func()
+	}{
+		{
+			name:     "not an interface",
+			register: func() { RegisterVariant[registerTestEventA, registerTestEventA]("type", "a") },
+		},
+		{
+			name:     "not implemented",
+			register: func() { RegisterVariant[registerTestEvent, string]("type", "b") },
+		},
+		{
+			name:     "key conflict",
+			register: func() { RegisterVariant[registerTestEvent, registerTestEventB]("kind", "b") },
+		},
+		{
+			name:     "value conflict",
+			register: func() { RegisterVariant[registerTestEvent, registerTestEventB]("type", "a") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				if recover() == nil {
+					t.Errorf("got no panic")
+				}
+			}()
+
+			tt.register()
+		})
+	}
+}
+
+func BenchmarkParser_Decode(b *testing.B) {
+	bs := []byte(`
+{
+    "int": 123,
+    "string": "🍣😋🍺",
+    "pointer": 123.456,
+    "slice": [1, 2, 3],
+    "map": {
+        "key1": "value1",
+        "key2": 2
+    },
+    "raw": [null, true, {"key": "value"}],
+    "Untagged": true
+}
+`)
+
+	r := bytes.NewReader(bs)
+	pa := NewParser(r)
+
+	b.ResetTimer()
+	for b.Loop() {
+		r.Reset(bs)
+		pa.reset()
+
+		var v decodeTestObject
+		if err := pa.Decode(&v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
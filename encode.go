@@ -0,0 +1,654 @@
+package mocjson
+
+import (
+	"bytes"
+	"cmp"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const EncoderBufSize = 1024
+
+var (
+	marshalerType     = reflect.TypeFor[Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Marshaler is implemented by types which encode themselves into the live
+// Encoder. MarshalMocJSON must encode exactly one value.
+type Marshaler interface {
+	MarshalMocJSON(*Encoder) error
+}
+
+type Encoder struct {
+	w   io.Writer
+	buf []byte
+	err error
+
+	// canonical enables the canonical form. See EncoderOptions.
+	canonical bool
+}
+
+func NewEncoder(w io.Writer) Encoder {
+	return Encoder{w: w}
+}
+
+// EncoderOptions configures an Encoder.
+type EncoderOptions struct {
+	// Canonical encodes the values with EncodeValue and Encode in the JSON
+	// Canonicalization Scheme (RFC 8785) as Canonicalize does. The fields of
+	// structs are sorted by their names too. The methods for the tokens, such
+	// as EncodeBeginObject, write the tokens as they are.
+	Canonical bool
+}
+
+func NewEncoderWithOptions(w io.Writer, opts EncoderOptions) Encoder {
+	en := NewEncoder(w)
+	en.canonical = opts.Canonical
+
+	return en
+}
+
+// reset is called for testing.
+func (en *Encoder) reset() {
+	en.buf = en.buf[:0]
+	en.err = nil
+}
+
+func (en *Encoder) Flush() error {
+	if en.err != nil {
+		return en.err
+	}
+
+	if len(en.buf) > 0 {
+		_, en.err = en.w.Write(en.buf)
+		en.buf = en.buf[:0]
+	}
+
+	return en.err
+}
+
+func (en *Encoder) Err() error {
+	return en.err
+}
+
+// write flushes the buffer if it exceeds EncoderBufSize. It returns the
+// sticky error.
+func (en *Encoder) write() error {
+	if len(en.buf) >= EncoderBufSize {
+		return en.Flush()
+	}
+
+	return en.err
+}
+
+func (en *Encoder) Encode(v any) error {
+	if err := en.EncodeValue(v); err != nil {
+		return fmt.Errorf("encode error: %w", err)
+	}
+
+	if err := en.Flush(); err != nil {
+		return fmt.Errorf("writer error: %w", err)
+	}
+
+	return nil
+}
+
+func (en *Encoder) EncodeValue(v any) error {
+	if en.canonical {
+		return en.encodeCanonical(v)
+	}
+
+	if v == nil {
+		return en.EncodeNull()
+	}
+
+	return en.encodeValue(reflect.ValueOf(v))
+}
+
+func (en *Encoder) EncodeBeginArray() error {
+	en.buf = append(en.buf, '[')
+	return en.write()
+}
+
+func (en *Encoder) EncodeEndArray() error {
+	en.buf = append(en.buf, ']')
+	return en.write()
+}
+
+func (en *Encoder) EncodeBeginObject() error {
+	en.buf = append(en.buf, '{')
+	return en.write()
+}
+
+func (en *Encoder) EncodeEndObject() error {
+	en.buf = append(en.buf, '}')
+	return en.write()
+}
+
+func (en *Encoder) EncodeNameSeparator() error {
+	en.buf = append(en.buf, ':')
+	return en.write()
+}
+
+func (en *Encoder) EncodeValueSeparator() error {
+	en.buf = append(en.buf, ',')
+	return en.write()
+}
+
+func (en *Encoder) EncodeNull() error {
+	en.buf = append(en.buf, "null"...)
+	return en.write()
+}
+
+func (en *Encoder) EncodeBool(b bool) error {
+	en.buf = strconv.AppendBool(en.buf, b)
+	return en.write()
+}
+
+func (en *Encoder) EncodeInt64(n int64) error {
+	en.buf = strconv.AppendInt(en.buf, n, 10)
+	return en.write()
+}
+
+func (en *Encoder) EncodeUint64(n uint64) error {
+	en.buf = strconv.AppendUint(en.buf, n, 10)
+	return en.write()
+}
+
+func (en *Encoder) EncodeFloat64(f float64) error {
+	return en.encodeFloat(f, 64)
+}
+
+func (en *Encoder) encodeFloat(f float64, bitSize int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("unsupported float: %v", f)
+	}
+
+	en.buf = appendFloat(en.buf, f, bitSize)
+	return en.write()
+}
+
+// appendFloat formats f in the shortest form like ECMAScript does.
+func appendFloat(b []byte, f float64, bitSize int) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bitSize == 64 && (abs < 1e-6 || abs >= 1e21) ||
+			bitSize == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+
+	b = strconv.AppendFloat(b, f, format, -1, bitSize)
+
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+
+	return b
+}
+
+func (en *Encoder) EncodeRat(r *big.Rat) error {
+	prec, exact := r.FloatPrec()
+	if !exact {
+		return fmt.Errorf("inexact rat: %v", r)
+	}
+
+	en.buf = append(en.buf, r.FloatString(prec)...)
+	return en.write()
+}
+
+func (en *Encoder) EncodeString(s string) error {
+	en.buf = appendString(en.buf, s)
+	return en.write()
+}
+
+func appendString(b []byte, s string) []byte {
+	b = append(b, '"')
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"':
+				b = append(b, `\"`...)
+			case c == '\\':
+				b = append(b, `\\`...)
+			case c == '\b':
+				b = append(b, `\b`...)
+			case c == '\f':
+				b = append(b, `\f`...)
+			case c == '\n':
+				b = append(b, `\n`...)
+			case c == '\r':
+				b = append(b, `\r`...)
+			case c == '\t':
+				b = append(b, `\t`...)
+			case c < 0x20:
+				b = append(b, `\u00`...)
+				b = append(b, "0123456789abcdef"[c>>4], "0123456789abcdef"[c&0xF])
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			// broken multi-byte utf-8
+			b = utf8.AppendRune(b, utf8.RuneError)
+		} else {
+			b = append(b, s[i:i+size]...)
+		}
+		i += size
+	}
+
+	return append(b, '"')
+}
+
+func (en *Encoder) EncodeRaw(raw RawValue) error {
+	pa := NewParser(bytes.NewReader(raw))
+	if _, err := pa.ParseRaw(); err != nil {
+		return fmt.Errorf("invalid raw value: %w", err)
+	}
+	if !pa.lx.ExpectEOF() {
+		return errors.New("invalid raw value: expect EOF")
+	}
+
+	en.buf = append(en.buf, raw...)
+	return en.write()
+}
+
+func (en *Encoder) encodeValue(rv reflect.Value) error {
+	if rv.Kind() != reflect.Interface && rv.Type().Implements(marshalerType) {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return en.EncodeNull()
+		}
+		return rv.Interface().(Marshaler).MarshalMocJSON(en)
+	}
+	if rv.CanAddr() && rv.Addr().Type().Implements(marshalerType) {
+		return rv.Addr().Interface().(Marshaler).MarshalMocJSON(en)
+	}
+
+	switch rv.Type() {
+	case rawValueType:
+		if rv.IsNil() {
+			return en.EncodeNull()
+		}
+		return en.EncodeRaw(rv.Bytes())
+
+	case ratType:
+		if rv.CanAddr() {
+			return en.EncodeRat(rv.Addr().Interface().(*big.Rat))
+		}
+		r := rv.Interface().(big.Rat)
+		return en.EncodeRat(&r)
+
+	case reflect.PointerTo(ratType):
+		if rv.IsNil() {
+			return en.EncodeNull()
+		}
+		return en.EncodeRat(rv.Interface().(*big.Rat))
+	}
+
+	if rv.Kind() != reflect.Interface && rv.Type().Implements(textMarshalerType) {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return en.EncodeNull()
+		}
+		return en.encodeText(rv.Interface().(encoding.TextMarshaler))
+	}
+	if rv.CanAddr() && rv.Addr().Type().Implements(textMarshalerType) {
+		return en.encodeText(rv.Addr().Interface().(encoding.TextMarshaler))
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return en.EncodeBool(rv.Bool())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return en.EncodeInt64(rv.Int())
+
+	case reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		return en.EncodeUint64(rv.Uint())
+
+	case reflect.Float32:
+		return en.encodeFloat(rv.Float(), 32)
+
+	case reflect.Float64:
+		return en.encodeFloat(rv.Float(), 64)
+
+	case reflect.String:
+		return en.EncodeString(rv.String())
+
+	case reflect.Interface:
+		return en.encodeInterface(rv)
+
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return en.EncodeNull()
+		}
+		return en.encodeValue(rv.Elem())
+
+	case reflect.Slice:
+		if rv.IsNil() {
+			return en.EncodeNull()
+		}
+		return en.encodeArray(rv)
+
+	case reflect.Array:
+		return en.encodeArray(rv)
+
+	case reflect.Map:
+		return en.encodeMap(rv)
+
+	case reflect.Struct:
+		return en.encodeStruct(rv, "", "")
+
+	default:
+		return fmt.Errorf("unsupported type: %v", rv.Type())
+	}
+}
+
+func (en *Encoder) encodeInterface(rv reflect.Value) error {
+	if rv.IsNil() {
+		return en.EncodeNull()
+	}
+
+	u, ok := lookupUnion(rv.Type())
+	if !ok {
+		return en.encodeValue(rv.Elem())
+	}
+
+	return en.encodeVariant(rv.Elem(), u)
+}
+
+// encodeVariant encodes a union variant. The discriminator is written first
+// unless the variant has its own field for it.
+func (en *Encoder) encodeVariant(rv reflect.Value, u *union) error {
+	d, ok := u.values[rv.Type()]
+	if !ok {
+		return en.encodeValue(rv)
+	}
+
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return en.EncodeNull()
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct || rv.Type().Implements(marshalerType) {
+		return en.encodeValue(rv)
+	}
+
+	si, err := cachedStructInfo(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	if i, ok := si.byName[u.key]; ok && si.fields[i].name == u.key {
+		return en.encodeStruct(rv, "", "")
+	}
+
+	return en.encodeStruct(rv, u.key, d)
+}
+
+func (en *Encoder) encodeArray(rv reflect.Value) error {
+	if err := en.EncodeBeginArray(); err != nil {
+		return err
+	}
+
+	for i := range rv.Len() {
+		if i > 0 {
+			if err := en.EncodeValueSeparator(); err != nil {
+				return err
+			}
+		}
+
+		if err := en.encodeValue(rv.Index(i)); err != nil {
+			return fmt.Errorf("encode [%d] error: %w", i, err)
+		}
+	}
+
+	return en.EncodeEndArray()
+}
+
+func (en *Encoder) encodeText(tm encoding.TextMarshaler) error {
+	b, err := tm.MarshalText()
+	if err != nil {
+		return fmt.Errorf("marshal text error: %w", err)
+	}
+
+	return en.EncodeString(string(b))
+}
+
+func (en *Encoder) encodeMap(rv reflect.Value) error {
+	if !isEncodableMapKey(rv.Type().Key()) {
+		return fmt.Errorf("unsupported map key type: %v", rv.Type().Key())
+	}
+
+	if rv.IsNil() {
+		return en.EncodeNull()
+	}
+
+	type member struct {
+		k string
+		v reflect.Value
+	}
+
+	members := make([]member, 0, rv.Len())
+	for it := rv.MapRange(); it.Next(); {
+		k, err := encodeMapKey(it.Key())
+		if err != nil {
+			return fmt.Errorf("encode key error: %w", err)
+		}
+		members = append(members, member{k: k, v: it.Value()})
+	}
+
+	slices.SortFunc(members, func(a, b member) int {
+		return cmp.Compare(a.k, b.k)
+	})
+
+	if err := en.EncodeBeginObject(); err != nil {
+		return err
+	}
+
+	for i, m := range members {
+		if i > 0 {
+			if err := en.EncodeValueSeparator(); err != nil {
+				return err
+			}
+		}
+
+		if err := en.EncodeString(m.k); err != nil {
+			return err
+		}
+		if err := en.EncodeNameSeparator(); err != nil {
+			return err
+		}
+		if err := en.encodeValue(m.v); err != nil {
+			return fmt.Errorf("encode %s error: %w", m.k, err)
+		}
+	}
+
+	return en.EncodeEndObject()
+}
+
+func isEncodableMapKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64,
+		reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		return true
+	}
+
+	return t.Implements(textMarshalerType)
+}
+
+// encodeMapKey encodes k as encoding/json does: strings are used as they are,
+// and TextMarshaler takes precedence over the integer kinds.
+func encodeMapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+
+	if k.Type().Implements(textMarshalerType) {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+
+		b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("marshal text error: %w", err)
+		}
+
+		return string(b), nil
+	}
+
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+
+	case reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+
+	return "", fmt.Errorf("unsupported map key type: %v", k.Type())
+}
+
+// encodeStruct encodes the struct fields. If key is not empty, the key and
+// the string value are written before the fields.
+func (en *Encoder) encodeStruct(rv reflect.Value, key, value string) error {
+	si, err := cachedStructInfo(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	if err := en.EncodeBeginObject(); err != nil {
+		return err
+	}
+
+	first := true
+
+	if key != "" {
+		first = false
+
+		if err := en.EncodeString(key); err != nil {
+			return err
+		}
+		if err := en.EncodeNameSeparator(); err != nil {
+			return err
+		}
+		if err := en.EncodeString(value); err != nil {
+			return err
+		}
+	}
+
+	for i := range si.fields {
+		f := &si.fields[i]
+
+		fv, ok := fieldByIndexNoAlloc(rv, f.index)
+		if !ok || f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+
+		if !first {
+			if err := en.EncodeValueSeparator(); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		if err := en.EncodeString(f.name); err != nil {
+			return err
+		}
+		if err := en.EncodeNameSeparator(); err != nil {
+			return err
+		}
+		if err := en.encodeField(fv, f); err != nil {
+			return fmt.Errorf("encode %s error: %w", f.name, err)
+		}
+	}
+
+	return en.EncodeEndObject()
+}
+
+func (en *Encoder) encodeField(rv reflect.Value, f *fieldInfo) error {
+	if f.timeLayout == "" && !f.duration {
+		return en.encodeValue(rv)
+	}
+
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return en.EncodeNull()
+		}
+		rv = rv.Elem()
+	}
+
+	if f.duration {
+		return en.EncodeDuration(time.Duration(rv.Int()))
+	}
+
+	return en.EncodeTime(rv.Interface().(time.Time), f.timeLayout)
+}
+
+func isEmptyValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Bool,
+		reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64,
+		reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr,
+		reflect.Float32,
+		reflect.Float64,
+		reflect.Interface,
+		reflect.Pointer:
+		return rv.IsZero()
+	}
+
+	return false
+}
@@ -0,0 +1,469 @@
+package mocjson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPoint is encoded as [x,y].
+type testPoint struct {
+	X, Y float64
+}
+
+func (p *testPoint) UnmarshalMocJSON(pa *Parser) error {
+	lx := pa.Lexer()
+
+	if !lx.ExpectBeginArray() {
+		return errors.New("expect begin array")
+	}
+
+	x, err := pa.ParseFloat64()
+	if err != nil {
+		return err
+	}
+
+	if !lx.ExpectValueSeparator() {
+		return errors.New("expect value separator")
+	}
+
+	y, err := pa.ParseFloat64()
+	if err != nil {
+		return err
+	}
+
+	if !lx.ExpectEndArray() {
+		return errors.New("expect end array")
+	}
+
+	p.X, p.Y = x, y
+	return nil
+}
+
+func (p testPoint) MarshalMocJSON(en *Encoder) error {
+	if err := en.EncodeBeginArray(); err != nil {
+		return err
+	}
+	if err := en.EncodeFloat64(p.X); err != nil {
+		return err
+	}
+	if err := en.EncodeValueSeparator(); err != nil {
+		return err
+	}
+	if err := en.EncodeFloat64(p.Y); err != nil {
+		return err
+	}
+	return en.EncodeEndArray()
+}
+
+type encodeTestObject struct {
+	Bool     bool           `json:"bool"`
+	Int      int8           `json:"int"`
+	Uint     uint           `json:"uint,omitempty"`
+	Float    float32        `json:"float"`
+	String   string         `json:"string,omitempty"`
+	Pointer  *string        `json:"pointer"`
+	Slice    []int          `json:"slice"`
+	Array    [2]bool        `json:"array"`
+	Map      map[string]any `json:"map,omitempty"`
+	Raw      RawValue       `json:"raw,omitempty"`
+	Point    testPoint      `json:"point"`
+	Ignored  string         `json:"-"`
+	Untagged bool
+	private  bool
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		v       any
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "nil",
+			v:    nil,
+			want: "null",
+		},
+		{
+			name: "bool",
+			v:    true,
+			want: "true",
+		},
+		{
+			name: "int",
+			v:    -123,
+			want: "-123",
+		},
+		{
+			name: "uint64",
+			v:    uint64(math.MaxUint64),
+			want: "18446744073709551615",
+		},
+		{
+			name: "float64",
+			v:    123.456,
+			want: "123.456",
+		},
+		{
+			name: "float64 integer",
+			v:    1.0,
+			want: "1",
+		},
+		{
+			name: "float64 small",
+			v:    1e-7,
+			want: "1e-7",
+		},
+		{
+			name: "float64 large",
+			v:    1e21,
+			want: "1e+21",
+		},
+		{
+			name: "float32",
+			v:    float32(0.1),
+			want: "0.1",
+		},
+		{
+			name:    "NaN",
+			v:       math.NaN(),
+			wantErr: true,
+		},
+		{
+			name:    "Inf",
+			v:       math.Inf(1),
+			wantErr: true,
+		},
+		{
+			name: "rat",
+			v:    big.NewRat(1, 8),
+			want: "0.125",
+		},
+		{
+			name:    "inexact rat",
+			v:       big.NewRat(1, 3),
+			wantErr: true,
+		},
+		{
+			name: "string",
+			v:    "hello \"world\"\\\n\t\x00\x1f 🍣\xff",
+			want: `"hello \"world\"\\\n\t\u0000\u001f 🍣` + "�" + `"`,
+		},
+		{
+			name: "nil slice",
+			v:    []int(nil),
+			want: "null",
+		},
+		{
+			name: "slice",
+			v:    []any{nil, true, "value", 1.5, []any{}, map[string]any{}},
+			want: `[null,true,"value",1.5,[],{}]`,
+		},
+		{
+			name: "map",
+			v:    map[string]int{"key2": 2, "key1": 1, "key3": 3},
+			want: `{"key1":1,"key2":2,"key3":3}`,
+		},
+		{
+			name: "raw",
+			v:    RawValue(`{"key": [1, 2]}`),
+			want: `{"key": [1, 2]}`,
+		},
+		{
+			name:    "invalid raw",
+			v:       RawValue(`{"key": [1, 2]`),
+			wantErr: true,
+		},
+		{
+			name: "marshaler",
+			v:    testPoint{X: 1, Y: 2.5},
+			want: `[1,2.5]`,
+		},
+		{
+			name: "struct",
+			v: encodeTestObject{
+				Bool:     true,
+				Int:      -1,
+				Float:    1.5,
+				Slice:    []int{1, 2},
+				Map:      map[string]any{"key": "value"},
+				Raw:      RawValue("[true]"),
+				Point:    testPoint{X: 1, Y: 2},
+				Ignored:  "ignored",
+				Untagged: true,
+			},
+			want: `{"bool":true,"int":-1,"float":1.5,"pointer":null,"slice":[1,2],"array":[false,false],"map":{"key":"value"},"raw":[true],"point":[1,2],"Untagged":true}`,
+		},
+		{
+			name: "embedded",
+			v: decodeTestEmbedded{
+				decodeTestInner:  decodeTestInner{ID: 1, Name: "inner", Comment: "hello"},
+				decodeTestTagged: decodeTestTagged{Tag: "tag"},
+				ID:               2,
+			},
+			want: `{"comment":"hello","Tag":"tag","id":2}`,
+		},
+		{
+			name: "embedded pointer",
+			v: decodeTestEmbedded{
+				decodeTestOther: &decodeTestOther{Name: "other", Tag: "other"},
+			},
+			want: `{"Tag":"","id":0}`,
+		},
+		{
+			name: "nil embedded pointer",
+			v:    decodeTestPointerEmbedded{Extra: "e"},
+			want: `{"extra":"e"}`,
+		},
+		{
+			name: "named embedded",
+			v:    decodeTestNamedEmbedded{decodeTestInner{ID: 1, Name: "hello"}},
+			want: `{"inner":{"id":1,"name":"hello"}}`,
+		},
+		{
+			name: "union",
+			v: []SampleEvent{
+				SampleCreatedEvent{ID: "1", Name: "hello"},
+				SampleDeletedEvent{ID: "2"},
+			},
+			want: `[{"kind":"created","id":"1","name":"hello"},{"kind":"deleted","id":"2","reason":""}]`,
+		},
+		{
+			name: "int map key",
+			v:    map[int64]string{10: "b", -1: "a", 2: "c"},
+			want: `{"-1":"a","10":"b","2":"c"}`,
+		},
+		{
+			name: "uint32 map key",
+			v:    map[uint32]bool{4294967295: true},
+			want: `{"4294967295":true}`,
+		},
+		{
+			name: "named string map key",
+			v:    map[decodeTestKey]int{"key": 1},
+			want: `{"key":1}`,
+		},
+		{
+			name: "text marshaler",
+			v:    netip.MustParseAddr("2001:db8::1"),
+			want: `"2001:db8::1"`,
+		},
+		{
+			name: "text marshaler time",
+			v:    []*time.Time{{}, nil},
+			want: `["0001-01-01T00:00:00Z",null]`,
+		},
+		{
+			name: "text marshaler map key",
+			v: map[netip.Addr]int{
+				netip.MustParseAddr("192.0.2.2"): 2,
+				netip.MustParseAddr("192.0.2.1"): 1,
+			},
+			want: `{"192.0.2.1":1,"192.0.2.2":2}`,
+		},
+		{
+			name: "text marshaler int map key",
+			v:    map[encodeTestLevel]int{1: 2},
+			want: `{"L1":2}`,
+		},
+		{
+			name:    "text marshaler error",
+			v:       time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			v:       func() {},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported map key",
+			v:       map[bool]int{true: 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			en := NewEncoder(&buf)
+
+			err := en.Encode(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_Encode_LongValue(t *testing.T) {
+	t.Parallel()
+
+	v := strings.Repeat("🍣😋🍺", EncoderBufSize)
+
+	var buf bytes.Buffer
+	en := NewEncoder(&buf)
+
+	if err := en.Encode([]string{v, v}); err != nil {
+		t.Fatal(err)
+	}
+
+	pa := NewParser(&buf)
+	got, err := pa.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := got.([]any); len(s) != 2 || s[0] != v || s[1] != v {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestEncoder_Encode_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want := map[string]testPoint{"origin": {}, "point": {X: 1.5, Y: -2}}
+
+	var buf bytes.Buffer
+	en := NewEncoder(&buf)
+	if err := en.Encode(want); err != nil {
+		t.Fatal(err)
+	}
+
+	pa := NewParser(&buf)
+	var got map[string]testPoint
+	if err := pa.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(want) || got["origin"] != want["origin"] || got["point"] != want["point"] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// encodeTestLevel is an integer key type with its own text form.
+type encodeTestLevel int
+
+func (l encodeTestLevel) MarshalText() ([]byte, error) {
+	return fmt.Appendf(nil, "L%d", l), nil
+}
+
+func (l *encodeTestLevel) UnmarshalText(b []byte) error {
+	n, err := strconv.Atoi(strings.TrimPrefix(string(b), "L"))
+	if err != nil {
+		return err
+	}
+	*l = encodeTestLevel(n)
+	return nil
+}
+
+func TestEncoder_Encode_TextMarshalerMapKeyRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want := map[encodeTestLevel]int{1: 2, -3: 4}
+
+	var buf bytes.Buffer
+	en := NewEncoder(&buf)
+	if err := en.Encode(want); err != nil {
+		t.Fatal(err)
+	}
+
+	pa := NewParser(&buf)
+	var got map[encodeTestLevel]int
+	if err := pa.Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+type encodeTestShape interface {
+	encodeTestShape()
+}
+
+type encodeTestCircle struct {
+	R int `json:"r"`
+}
+
+func (encodeTestCircle) encodeTestShape() {}
+
+func TestEncoder_Encode_VariantAlias(t *testing.T) {
+	t.Parallel()
+
+	RegisterVariant[encodeTestShape, encodeTestCircle]("type", "circle")
+	RegisterVariant[encodeTestShape, encodeTestCircle]("type", "round")
+
+	// Both values decode to the variant.
+	for _, s := range []string{`{"type":"circle","r":1}`, `{"type":"round","r":1}`} {
+		pa := NewParser(strings.NewReader(s))
+		var v encodeTestShape
+		if err := pa.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		if v != (encodeTestCircle{R: 1}) {
+			t.Errorf("got %v", v)
+		}
+	}
+
+	// The first value is always encoded.
+	want := `{"type":"circle","r":1}`
+	for range 100 {
+		var buf bytes.Buffer
+		en := NewEncoder(&buf)
+		if err := en.Encode([]encodeTestShape{encodeTestCircle{R: 1}}); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != "["+want+"]" {
+			t.Fatalf("got %s, want [%s]", got, want)
+		}
+	}
+}
+
+func BenchmarkEncoder_Encode(b *testing.B) {
+	s := "🍣😋🍺"
+	v := encodeTestObject{
+		Bool:     true,
+		Int:      -1,
+		Uint:     123,
+		Float:    123.456,
+		String:   "hello\nworld",
+		Pointer:  &s,
+		Slice:    []int{1, 2, 3},
+		Map:      map[string]any{"key1": "value1", "key2": 2.0},
+		Raw:      RawValue(`[null, true, {"key": "value"}]`),
+		Point:    testPoint{X: 1, Y: 2},
+		Untagged: true,
+	}
+
+	var buf bytes.Buffer
+	en := NewEncoder(&buf)
+
+	b.ResetTimer()
+	for b.Loop() {
+		buf.Reset()
+		en.reset()
+		if err := en.Encode(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
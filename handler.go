@@ -0,0 +1,175 @@
+package mocjson
+
+import (
+	"errors"
+)
+
+// Handler receives the events of a value from Parser.Walk. The byte slices
+// are valid only during the call and must not be modified. An error returned
+// by a callback stops the walk, and Walk returns it as it is.
+type Handler interface {
+	BeginObject() error
+	Key(k []byte) error
+	EndObject() error
+	BeginArray() error
+	EndArray() error
+	String(s []byte) error
+	// Number receives the number as it is in the input, e.g. "-1.5e3".
+	Number(n []byte) error
+	Bool(b bool) error
+	Null() error
+}
+
+// Walk reads the next value and calls the methods of h for it in the input
+// order, without building the value. Nested values are walked with a stack
+// instead of recursion, so the depth is not limited by the goroutine stack.
+func (pa *Parser) Walk(h Handler) error {
+	// stack holds whether each open container is an object.
+	var stack []bool
+
+	for {
+		closed, err := pa.walkValue(h, &stack)
+		if err != nil {
+			return err
+		}
+		if !closed {
+			// The value has opened a non-empty container.
+			continue
+		}
+
+		// Close the containers which end after the value.
+		for {
+			if len(stack) == 0 {
+				return nil
+			}
+			isObject := stack[len(stack)-1]
+
+			tt := pa.lx.NextTokenType()
+
+			if tt == TokenTypeValueSeparator {
+				pa.lx.sc.Skip(1)
+
+				end := TokenTypeEndArray
+				if isObject {
+					end = TokenTypeEndObject
+				}
+
+				if !pa.lx.TrailingComma(end) {
+					if isObject {
+						if err := pa.walkKey(h); err != nil {
+							return err
+						}
+					}
+					break
+				}
+				tt = end
+			}
+
+			switch {
+			case isObject && tt == TokenTypeEndObject:
+				pa.lx.sc.Skip(1)
+				err = h.EndObject()
+			case !isObject && tt == TokenTypeEndArray:
+				pa.lx.sc.Skip(1)
+				err = h.EndArray()
+			case isObject:
+				return errors.New("expect value separator or end object")
+			default:
+				return errors.New("expect value separator or end array")
+			}
+			if err != nil {
+				return err
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// walkValue reads a scalar value or an empty container and reports true, or
+// opens a non-empty container, reads its first key if it is an object, and
+// reports false.
+func (pa *Parser) walkValue(h Handler, stack *[]bool) (bool, error) {
+	switch pa.lx.NextTokenType() {
+	case TokenTypeBeginObject:
+		pa.lx.sc.Skip(1)
+		if err := h.BeginObject(); err != nil {
+			return false, err
+		}
+
+		// empty object
+		if pa.lx.NextTokenType() == TokenTypeEndObject {
+			pa.lx.sc.Skip(1)
+			return true, h.EndObject()
+		}
+
+		*stack = append(*stack, true)
+		return false, pa.walkKey(h)
+
+	case TokenTypeBeginArray:
+		pa.lx.sc.Skip(1)
+		if err := h.BeginArray(); err != nil {
+			return false, err
+		}
+
+		// empty array
+		if pa.lx.NextTokenType() == TokenTypeEndArray {
+			pa.lx.sc.Skip(1)
+			return true, h.EndArray()
+		}
+
+		*stack = append(*stack, false)
+		return false, nil
+
+	case TokenTypeNull:
+		if !pa.lx.ExpectNull() {
+			return false, errors.New("expect null")
+		}
+		return true, h.Null()
+
+	case TokenTypeBool:
+		v, ok := pa.lx.ExpectBool()
+		if !ok {
+			return false, errors.New("expect bool")
+		}
+		return true, h.Bool(v)
+
+	case TokenTypeNumber:
+		v, ok := pa.lx.ExpectNumberBytes()
+		if !ok {
+			return false, errors.New("expect number")
+		}
+		return true, h.Number(v)
+
+	case TokenTypeString:
+		v, ok := pa.lx.ExpectStringBytes()
+		if !ok {
+			return false, errors.New("expect string")
+		}
+		return true, h.String(v)
+	}
+
+	return false, errors.New("invalid token type")
+}
+
+func (pa *Parser) walkKey(h Handler) error {
+	var (
+		k  []byte
+		ok bool
+	)
+	if pa.lx.json5 {
+		var s string
+		s, ok = pa.lx.expectKey()
+		k = []byte(s)
+	} else {
+		k, ok = pa.lx.ExpectStringBytes()
+	}
+	if !ok {
+		return errors.New("expect string")
+	}
+
+	if !pa.lx.ExpectNameSeparator() {
+		return errors.New("expect name separator")
+	}
+
+	return h.Key(k)
+}
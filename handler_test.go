@@ -0,0 +1,222 @@
+package mocjson
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordHandler records the events as strings.
+type recordHandler struct {
+	events []string
+	// failAt makes the callback of the event fail.
+	failAt string
+}
+
+var errRecordHandler = errors.New("record handler error")
+
+func (h *recordHandler) record(e string) error {
+	h.events = append(h.events, e)
+	if e == h.failAt {
+		return errRecordHandler
+	}
+	return nil
+}
+
+func (h *recordHandler) BeginObject() error    { return h.record("{") }
+func (h *recordHandler) Key(k []byte) error    { return h.record("key:" + string(k)) }
+func (h *recordHandler) EndObject() error      { return h.record("}") }
+func (h *recordHandler) BeginArray() error     { return h.record("[") }
+func (h *recordHandler) EndArray() error       { return h.record("]") }
+func (h *recordHandler) String(s []byte) error { return h.record("string:" + string(s)) }
+func (h *recordHandler) Number(n []byte) error { return h.record("number:" + string(n)) }
+
+func (h *recordHandler) Bool(b bool) error {
+	if b {
+		return h.record("true")
+	}
+	return h.record("false")
+}
+
+func (h *recordHandler) Null() error { return h.record("null") }
+
+func TestParser_Walk(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       string
+		failAt  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "scalars",
+			b:    `"a\nb"`,
+			want: []string{"string:a\nb"},
+		},
+		{
+			name: "number",
+			b:    `-1.5e3`,
+			want: []string{"number:-1.5e3"},
+		},
+		{
+			name: "empty containers",
+			b:    `[{},[]]`,
+			want: []string{"[", "{", "}", "[", "]", "]"},
+		},
+		{
+			name: "nested",
+			b:    ` { "a" : [ 1 , true , null ] , "b" : { "c" : false } , "d" : "x" } `,
+			want: []string{
+				"{",
+				"key:a", "[", "number:1", "true", "null", "]",
+				"key:b", "{", "key:c", "false", "}",
+				"key:d", "string:x",
+				"}",
+			},
+		},
+		{
+			name: "next value only",
+			b:    `[1] [2]`,
+			want: []string{"[", "number:1", "]"},
+		},
+		{
+			name:    "handler error",
+			b:       `{"a":1,"b":2}`,
+			failAt:  "key:b",
+			want:    []string{"{", "key:a", "number:1", "key:b"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid token",
+			b:       `[1,x]`,
+			want:    []string{"[", "number:1"},
+			wantErr: true,
+		},
+		{
+			name:    "trailing comma",
+			b:       `{"a":1,}`,
+			want:    []string{"{", "key:a", "number:1"},
+			wantErr: true,
+		},
+		{
+			name:    "mismatched end",
+			b:       `[1}`,
+			want:    []string{"[", "number:1"},
+			wantErr: true,
+		},
+		{
+			name:    "missing name separator",
+			b:       `{"a" 1}`,
+			want:    []string{"{"},
+			wantErr: true,
+		},
+		{
+			name:    "unterminated",
+			b:       `{"a":[1`,
+			want:    []string{"{", "key:a", "[", "number:1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParser(strings.NewReader(tt.b))
+			h := recordHandler{failAt: tt.failAt}
+
+			err := pa.Walk(&h)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.failAt != "" && !errors.Is(err, errRecordHandler) {
+				t.Errorf("got %v, want %v", err, errRecordHandler)
+			}
+			if !reflect.DeepEqual(h.events, tt.want) {
+				t.Errorf("got %q, want %q", h.events, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_Walk_JSON5(t *testing.T) {
+	t.Parallel()
+
+	pa := NewParserWithOptions(
+		strings.NewReader(`{a: ['b', 0x1F,], /* c */ 'c': {},}`),
+		ParserOptions{JSON5: true},
+	)
+	var h recordHandler
+
+	if err := pa.Walk(&h); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"{", "key:a", "[", "string:b", "number:0x1F", "]", "key:c", "{", "}", "}",
+	}
+	if !reflect.DeepEqual(h.events, want) {
+		t.Errorf("got %q, want %q", h.events, want)
+	}
+}
+
+func TestParser_Walk_DeeplyNested(t *testing.T) {
+	t.Parallel()
+
+	const depth = 1000000
+	b := strings.Repeat(`{"a":[`, depth) + strings.Repeat(`]}`, depth)
+
+	pa := NewParser(strings.NewReader(b))
+	var h countHandler
+	if err := pa.Walk(&h); err != nil {
+		t.Fatal(err)
+	}
+
+	if h.keys["a"] != depth {
+		t.Errorf("got %d keys, want %d", h.keys["a"], depth)
+	}
+}
+
+// countHandler counts the keys without keeping the values.
+type countHandler struct {
+	keys map[string]int
+}
+
+func (h *countHandler) BeginObject() error { return nil }
+
+func (h *countHandler) Key(k []byte) error {
+	if h.keys == nil {
+		h.keys = make(map[string]int)
+	}
+	h.keys[string(k)]++
+	return nil
+}
+
+func (h *countHandler) EndObject() error      { return nil }
+func (h *countHandler) BeginArray() error     { return nil }
+func (h *countHandler) EndArray() error       { return nil }
+func (h *countHandler) String(s []byte) error { return nil }
+func (h *countHandler) Number(n []byte) error { return nil }
+func (h *countHandler) Bool(b bool) error     { return nil }
+func (h *countHandler) Null() error           { return nil }
+
+func BenchmarkParser_Walk(b *testing.B) {
+	bs := []byte(`[` + strings.Repeat(`{"id":"a","kind":"deleted","n":[1,2.5,true,null]},`, 999) +
+		`{"id":"a","kind":"deleted","n":[1,2.5,true,null]}]`)
+
+	r := bytes.NewReader(bs)
+	pa := NewParser(r)
+	var h countHandler
+
+	for b.Loop() {
+		r.Reset(bs)
+		pa.reset()
+		if err := pa.Walk(&h); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
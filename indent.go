@@ -0,0 +1,238 @@
+package mocjson
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Indent reformats the JSON value read from src into dst. Each element of an
+// array or an object begins on a new line with prefix followed by copies of
+// indent for the nesting depth. The first line does not begin with prefix,
+// and empty arrays and objects stay on one line, as in encoding/json.
+//
+// The tokens are streamed from the Lexer without building the value, so the
+// memory does not grow with the size of the input. The bytes of numbers and
+// strings are written as they are in src. On an error, a part of the output
+// may have been written to dst.
+func Indent(dst io.Writer, src io.Reader, prefix, indent string) error {
+	f := formatter{
+		lx:     NewLexer(src),
+		en:     NewEncoder(dst),
+		prefix: prefix,
+		indent: indent,
+		pretty: true,
+	}
+
+	return f.format()
+}
+
+// Compact is like Indent, but writes the value without any white spaces.
+func Compact(dst io.Writer, src io.Reader) error {
+	f := formatter{lx: NewLexer(src), en: NewEncoder(dst)}
+
+	return f.format()
+}
+
+type formatter struct {
+	lx Lexer
+	en Encoder
+
+	prefix string
+	indent string
+	// pretty is false for Compact.
+	pretty bool
+}
+
+func (f *formatter) format() error {
+	if err := f.formatValues(); err != nil {
+		return fmt.Errorf("format error: %w", err)
+	}
+
+	if !f.lx.ExpectEOF() {
+		if err := f.lx.sc.Err(); err != nil && err != io.EOF {
+			return fmt.Errorf("scanner error: %w", err)
+		}
+		return errors.New("expect EOF")
+	}
+
+	if err := f.en.Flush(); err != nil {
+		return fmt.Errorf("writer error: %w", err)
+	}
+
+	return nil
+}
+
+// formatValues formats the value with a stack instead of recursion as Walk
+// does.
+func (f *formatter) formatValues() error {
+	// stack holds whether each open container is an object.
+	var stack []bool
+
+	for {
+		closed, err := f.formatValue(&stack)
+		if err != nil {
+			return err
+		}
+		if !closed {
+			// The value has opened a non-empty container.
+			continue
+		}
+
+		// Close the containers which end after the value.
+		for {
+			if len(stack) == 0 {
+				return nil
+			}
+			isObject := stack[len(stack)-1]
+
+			tt := f.lx.NextTokenType()
+
+			if tt == TokenTypeValueSeparator {
+				f.lx.sc.Skip(1)
+				f.en.buf = append(f.en.buf, ',')
+				f.newline(len(stack))
+				if isObject {
+					if err := f.formatKey(); err != nil {
+						return err
+					}
+				}
+				break
+			}
+
+			switch {
+			case isObject && tt == TokenTypeEndObject:
+				f.lx.sc.Skip(1)
+				f.newline(len(stack) - 1)
+				f.en.buf = append(f.en.buf, '}')
+			case !isObject && tt == TokenTypeEndArray:
+				f.lx.sc.Skip(1)
+				f.newline(len(stack) - 1)
+				f.en.buf = append(f.en.buf, ']')
+			case isObject:
+				return errors.New("expect value separator or end object")
+			default:
+				return errors.New("expect value separator or end array")
+			}
+			stack = stack[:len(stack)-1]
+
+			if err := f.en.write(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// formatValue writes the next value, or the beginning of it if it is a
+// non-empty array or object. It reports whether the value has been closed.
+func (f *formatter) formatValue(stack *[]bool) (bool, error) {
+	switch f.lx.NextTokenType() {
+	case TokenTypeBeginObject:
+		f.lx.sc.Skip(1)
+
+		// empty object
+		if f.lx.NextTokenType() == TokenTypeEndObject {
+			f.lx.sc.Skip(1)
+			f.en.buf = append(f.en.buf, "{}"...)
+			return true, f.en.write()
+		}
+
+		*stack = append(*stack, true)
+		f.en.buf = append(f.en.buf, '{')
+		f.newline(len(*stack))
+		return false, f.formatKey()
+
+	case TokenTypeBeginArray:
+		f.lx.sc.Skip(1)
+
+		// empty array
+		if f.lx.NextTokenType() == TokenTypeEndArray {
+			f.lx.sc.Skip(1)
+			f.en.buf = append(f.en.buf, "[]"...)
+			return true, f.en.write()
+		}
+
+		*stack = append(*stack, false)
+		f.en.buf = append(f.en.buf, '[')
+		f.newline(len(*stack))
+		return false, f.en.write()
+
+	case TokenTypeNull:
+		if !f.lx.ExpectNull() {
+			return false, errors.New("expect null")
+		}
+		f.en.buf = append(f.en.buf, "null"...)
+
+	case TokenTypeBool:
+		v, ok := f.lx.ExpectBool()
+		if !ok {
+			return false, errors.New("expect bool")
+		}
+		f.en.buf = strconv.AppendBool(f.en.buf, v)
+
+	case TokenTypeNumber:
+		v, ok := f.lx.ExpectNumberBytes()
+		if !ok {
+			return false, errors.New("expect number")
+		}
+		f.en.buf = append(f.en.buf, v...)
+
+	case TokenTypeString:
+		if err := f.formatString(); err != nil {
+			return false, err
+		}
+
+	default:
+		return false, errors.New("invalid token type")
+	}
+
+	return true, f.en.write()
+}
+
+func (f *formatter) formatKey() error {
+	if err := f.formatString(); err != nil {
+		return err
+	}
+
+	if !f.lx.ExpectNameSeparator() {
+		return errors.New("expect name separator")
+	}
+
+	if f.pretty {
+		f.en.buf = append(f.en.buf, ": "...)
+	} else {
+		f.en.buf = append(f.en.buf, ':')
+	}
+
+	return f.en.write()
+}
+
+// formatString writes the next string as it is in the input.
+func (f *formatter) formatString() error {
+	f.lx.skipWhiteSpaces()
+
+	mark := f.lx.sc.startRecording()
+	_, ok := f.lx.ExpectStringBytes()
+	b := f.lx.sc.stopRecording(mark)
+
+	if !ok {
+		return errors.New("expect string")
+	}
+
+	f.en.buf = append(f.en.buf, b...)
+	return nil
+}
+
+// newline begins a new line with the indentation for depth.
+func (f *formatter) newline(depth int) {
+	if !f.pretty {
+		return
+	}
+
+	f.en.buf = append(f.en.buf, '\n')
+	f.en.buf = append(f.en.buf, f.prefix...)
+	for range depth {
+		f.en.buf = append(f.en.buf, f.indent...)
+	}
+}
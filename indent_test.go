@@ -0,0 +1,138 @@
+package mocjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestIndent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       string
+		wantErr bool
+	}{
+		{
+			name: "scalar",
+			b:    ` 1.50E+3 `,
+		},
+		{
+			name: "preserved bytes",
+			b:    `{"é\/": ["😀", -0.0, 1e-7, "é"]}`,
+		},
+		{
+			name: "nested",
+			b: `{"a": [1, {"b": null, "c": [true, false]}, [], {}],
+				"d": {"e": [[ ]], "f": { } }, "g": ""}`,
+		},
+		{
+			name:    "trailing comma",
+			b:       `[1,]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing name separator",
+			b:       `{"a" 1}`,
+			wantErr: true,
+		},
+		{
+			name:    "key not string",
+			b:       `{1: 1}`,
+			wantErr: true,
+		},
+		{
+			name:    "unterminated",
+			b:       `[1, [2`,
+			wantErr: true,
+		},
+		{
+			name:    "trailing value",
+			b:       `[1] 2`,
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			b:       ``,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got bytes.Buffer
+			err := Indent(&got, iotest.OneByteReader(strings.NewReader(tt.b)), "> ", "\t")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var gotCompact bytes.Buffer
+			errCompact := Compact(&gotCompact, strings.NewReader(tt.b))
+			if (errCompact != nil) != tt.wantErr {
+				t.Fatalf("gotErr %v, wantErr %v", errCompact, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			// encoding/json keeps the trailing white spaces.
+			src := []byte(strings.TrimSpace(tt.b))
+
+			var want bytes.Buffer
+			if err := json.Indent(&want, src, "> ", "\t"); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("got %s, want %s", got.String(), want.String())
+			}
+
+			want.Reset()
+			if err := json.Compact(&want, src); err != nil {
+				t.Fatal(err)
+			}
+			if gotCompact.String() != want.String() {
+				t.Errorf("got %s, want %s", gotCompact.String(), want.String())
+			}
+		})
+	}
+}
+
+type errorWriter struct {
+	err error
+}
+
+func (w *errorWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestIndent_WriterError(t *testing.T) {
+	t.Parallel()
+
+	errWrite := errors.New("write error")
+	w := errorWriter{err: errWrite}
+	src := "[" + strings.Repeat(`"0123456789",`, EncoderBufSize) + "null]"
+
+	if err := Indent(&w, strings.NewReader(src), "", "  "); !errors.Is(err, errWrite) {
+		t.Errorf("got %v, want %v", err, errWrite)
+	}
+}
+
+func BenchmarkIndent(b *testing.B) {
+	s := "[" + strings.Repeat(`{"id":"a","n":1.50,"tags":["x","y"],"ok":true},`, 1000) + "null]"
+	r := strings.NewReader(s)
+
+	for b.Loop() {
+		r.Reset(s)
+		if err := Indent(io.Discard, r, "", "  "); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
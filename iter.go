@@ -0,0 +1,221 @@
+package mocjson
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+)
+
+// IterArray iterates over the elements of the next array without building the
+// array. It yields pa itself positioned at the beginning of each element,
+// which the caller may read, e.g. with ParseValue or Decode. An element which
+// is not read is skipped, and an element which is partially read is an error.
+// On an error, it yields the error and stops.
+func (pa *Parser) IterArray() iter.Seq2[*Parser, error] {
+	return func(yield func(*Parser, error) bool) {
+		if !pa.lx.ExpectBeginArray() {
+			yield(nil, errors.New("expect begin array"))
+			return
+		}
+
+		// empty array
+		if pa.lx.NextTokenType() == TokenTypeEndArray {
+			pa.lx.sc.Skip(1)
+			return
+		}
+
+		for {
+			if err := pa.yieldValue(func() bool { return yield(pa, nil) }); err != nil {
+				if err != errStopIteration {
+					yield(nil, err)
+				}
+				return
+			}
+
+			switch pa.lx.NextTokenType() {
+			case TokenTypeEndArray:
+				pa.lx.sc.Skip(1)
+				return
+
+			case TokenTypeValueSeparator:
+				pa.lx.sc.Skip(1)
+				if pa.lx.TrailingComma(TokenTypeEndArray) {
+					pa.lx.sc.Skip(1)
+					return
+				}
+
+			default:
+				yield(nil, errors.New("expect value separator or end array"))
+				return
+			}
+		}
+	}
+}
+
+// IterArray iterates over the elements of the next array in pa, decoding each
+// of them into a T with Decode. On an error, it yields the error and stops.
+func IterArray[T any](pa *Parser) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		i := 0
+
+		for p, err := range pa.IterArray() {
+			var v T
+			if err == nil {
+				err = PrependPath(p.Decode(&v), IndexPath(i))
+			}
+
+			if !yield(v, err) || err != nil {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// IterObject iterates over the members of the next object without building
+// the object. It yields each key with pa itself positioned at the beginning
+// of the value, which the caller may read, e.g. with ParseValue or Decode. A
+// value which is not read is skipped, and a value which is partially read is
+// an error. The keys are yielded in the input order, and duplicate keys are
+// not checked. On an error, it stops, and IterErr returns the error.
+func (pa *Parser) IterObject() iter.Seq2[string, *Parser] {
+	return func(yield func(string, *Parser) bool) {
+		pa.iterErr = pa.iterObject(yield)
+	}
+}
+
+func (pa *Parser) iterObject(yield func(string, *Parser) bool) error {
+	if !pa.lx.ExpectBeginObject() {
+		return errors.New("expect begin object")
+	}
+
+	// empty object
+	if pa.lx.NextTokenType() == TokenTypeEndObject {
+		pa.lx.sc.Skip(1)
+		return nil
+	}
+
+	for {
+		k, ok := pa.lx.expectKey()
+		if !ok {
+			return errors.New("expect string")
+		}
+
+		if !pa.lx.ExpectNameSeparator() {
+			return errors.New("expect name separator")
+		}
+
+		if err := pa.yieldValue(func() bool { return yield(k, pa) }); err != nil {
+			if err == errStopIteration {
+				return nil
+			}
+			return err
+		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndObject:
+			pa.lx.sc.Skip(1)
+			return nil
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndObject) {
+				pa.lx.sc.Skip(1)
+				return nil
+			}
+
+		default:
+			return errors.New("expect value separator or end object")
+		}
+	}
+}
+
+// IterErr returns the error which has stopped the last iteration of
+// IterObject, or nil.
+func (pa *Parser) IterErr() error {
+	return pa.iterErr
+}
+
+// errStopIteration is returned by yieldValue when the caller stops the
+// iteration.
+var errStopIteration = errors.New("stop iteration")
+
+// yieldValue calls yield with pa positioned at the beginning of a value, and
+// skips the value if yield has not read it. The bytes of an array or an object
+// are recorded while yield runs, so that a value which is partially read is
+// an error rather than leaving pa in the middle of it.
+func (pa *Parser) yieldValue(yield func() bool) error {
+	// The white spaces are skipped first, so that peeking the next token in
+	// yield is not taken for reading the value.
+	pa.lx.skipWhiteSpaces()
+	off := pa.lx.sc.off
+
+	typ := pa.lx.NextTokenType()
+	if typ != TokenTypeBeginArray && typ != TokenTypeBeginObject {
+		if !yield() {
+			return errStopIteration
+		}
+		if pa.lx.sc.off == off {
+			return pa.skipValue()
+		}
+		return nil
+	}
+
+	mark := pa.lx.sc.startRecording()
+	ok := yield()
+	read := pa.lx.sc.stopRecording(mark)
+
+	switch {
+	case !ok:
+		return errStopIteration
+	case len(read) == 0:
+		return pa.skipValue()
+	case !pa.lx.closesValue(read):
+		return errors.New("value is partially read")
+	}
+
+	return nil
+}
+
+// closesValue reports whether b, which the Lexer has read from the beginning
+// of an array or an object, ends exactly at the end of it.
+func (lx *Lexer) closesValue(b []byte) bool {
+	depth := 0
+
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; c {
+		case '[', '{':
+			depth++
+
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return i == len(b)-1
+			}
+
+		case '"', '\'':
+			// The Lexer has read the whole string, so it ends at the next
+			// unescaped quote.
+			for i++; i < len(b) && b[i] != c; i++ {
+				if b[i] == '\\' {
+					i++
+				}
+			}
+
+		case '/':
+			// Only a comment, which the Lexer has read as a whole, starts
+			// with a slash outside the strings.
+			end := []byte("*/")
+			if i+1 < len(b) && b[i+1] == '/' {
+				end = []byte("\n")
+			}
+			n := bytes.Index(b[i+2:], end)
+			if n < 0 {
+				return false
+			}
+			i += 2 + n + len(end) - 1
+		}
+	}
+
+	return false
+}
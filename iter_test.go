@@ -0,0 +1,565 @@
+package mocjson
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParser_IterArray(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       string
+		want    []any
+		wantErr bool
+	}{
+		{
+			name: "empty array",
+			b:    `[]`,
+			want: nil,
+		},
+		{
+			name: "values",
+			b:    ` [ null , true , 1.5 , "a" , [ 1 ] , { "a" : 1 } ] `,
+			want: []any{nil, true, 1.5, "a", []any{1.0}, map[string]any{"a": 1.0}},
+		},
+		{
+			name:    "not array",
+			b:       `{}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid element",
+			b:       `[1,x]`,
+			want:    []any{1.0},
+			wantErr: true,
+		},
+		{
+			name:    "trailing comma",
+			b:       `[1,]`,
+			want:    []any{1.0},
+			wantErr: true,
+		},
+		{
+			name:    "missing separator",
+			b:       `[1 2]`,
+			want:    []any{1.0},
+			wantErr: true,
+		},
+		{
+			name:    "unterminated",
+			b:       `[1`,
+			want:    []any{1.0},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParser(strings.NewReader(tt.b))
+
+			var got []any
+			var gotErr error
+			for p, err := range pa.IterArray() {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				v, err := p.ParseValue()
+				if err != nil {
+					gotErr = err
+					break
+				}
+				got = append(got, v)
+			}
+
+			if (gotErr != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", gotErr, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !pa.lx.ExpectEOF() {
+				t.Errorf("expect EOF")
+			}
+		})
+	}
+}
+
+func TestParser_IterArray_Break(t *testing.T) {
+	t.Parallel()
+
+	pa := NewParser(strings.NewReader(`[1,2,3] 4`))
+
+	for p, err := range pa.IterArray() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.ParseValue(); err != nil {
+			t.Fatal(err)
+		}
+		break
+	}
+
+	// The parser stays after the first element.
+	if !pa.lx.ExpectValueSeparator() {
+		t.Errorf("expect value separator")
+	}
+}
+
+func TestParser_IterArray_Many(t *testing.T) {
+	t.Parallel()
+
+	const n = 100000
+	b := `[` + strings.Repeat(`{"id":"a","kind":"deleted"},`, n-1) + `{"id":"a","kind":"deleted"}]`
+
+	pa := NewParser(strings.NewReader(b))
+	count := 0
+	for p, err := range pa.IterArray() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.ParseSampleEvent(); err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+
+	if count != n {
+		t.Errorf("got %d elements, want %d", count, n)
+	}
+}
+
+func TestIterArray(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name string `json:"name" mocjson:"minLength=1"`
+	}
+
+	tests := []struct {
+		name     string
+		b        string
+		want     []item
+		wantErr  bool
+		wantPath string
+	}{
+		{
+			name: "items",
+			b:    `[{"name":"a"},{"name":"b"}]`,
+			want: []item{{"a"}, {"b"}},
+		},
+		{
+			name:    "unknown key",
+			b:       `[{"name":"a"},{"value":"b"}]`,
+			want:    []item{{"a"}},
+			wantErr: true,
+		},
+		{
+			name:     "validation",
+			b:        `[{"name":"a"},{"name":"b"},{"name":""}]`,
+			want:     []item{{"a"}, {"b"}},
+			wantErr:  true,
+			wantPath: "[2].name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParser(strings.NewReader(tt.b))
+
+			var got []item
+			var gotErr error
+			for v, err := range IterArray[item](&pa) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				got = append(got, v)
+			}
+
+			if (gotErr != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", gotErr, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			var ve *ValidationError
+			if tt.wantPath != "" && (!errors.As(gotErr, &ve) || ve.Path != tt.wantPath) {
+				t.Errorf("got %v, want path %s", gotErr, tt.wantPath)
+			}
+		})
+	}
+}
+
+func BenchmarkParser_IterArray(b *testing.B) {
+	bs := []byte(
+		`[` + strings.Repeat(`{"id":"a","kind":"deleted"},`, 999) + `{"id":"a","kind":"deleted"}]`,
+	)
+
+	r := bytes.NewReader(bs)
+	pa := NewParser(r)
+
+	for b.Loop() {
+		r.Reset(bs)
+		pa.reset()
+		for p, err := range pa.IterArray() {
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := p.ParseSampleEvent(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestParser_IterArray_Skip(t *testing.T) {
+	t.Parallel()
+
+	pa := NewParser(strings.NewReader(`[ {"a":[1,2]} , "b" , 3 ]`))
+
+	var got []any
+	for p, err := range pa.IterArray() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Peeking is not reading.
+		if p.Lexer().NextTokenType() != TokenTypeNumber {
+			continue
+		}
+		v, err := p.ParseValue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+
+	if want := []any{3.0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if !pa.lx.ExpectEOF() {
+		t.Errorf("expect EOF")
+	}
+}
+
+func TestParser_IterArray_PartiallyRead(t *testing.T) {
+	t.Parallel()
+
+	pa := NewParser(strings.NewReader(`[[1,2],[3]]`))
+
+	n := 0
+	var gotErr error
+	for p, err := range pa.IterArray() {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		n++
+		if !p.Lexer().ExpectBeginArray() {
+			t.Fatal("expect begin array")
+		}
+	}
+
+	if gotErr == nil {
+		t.Errorf("got nil, want error")
+	}
+	if n != 1 {
+		t.Errorf("got %d elements, want 1", n)
+	}
+}
+
+func TestParser_IterObject(t *testing.T) {
+	t.Parallel()
+
+	type member struct {
+		k string
+		v any
+	}
+
+	tests := []struct {
+		name    string
+		b       string
+		read    func(k string) bool
+		want    []member
+		wantErr bool
+	}{
+		{
+			name: "empty object",
+			b:    ` { } `,
+			want: nil,
+		},
+		{
+			name: "members",
+			b:    ` { "a" : 1 , "b" : [ true ] , "c" : { "d" : null } } `,
+			want: []member{{"a", 1.0}, {"b", []any{true}}, {"c", map[string]any{"d": nil}}},
+		},
+		{
+			name: "duplicate keys",
+			b:    `{"a":1,"a":2}`,
+			want: []member{{"a", 1.0}, {"a", 2.0}},
+		},
+		{
+			name: "skip",
+			b:    `{"a":{"x":[1,{"y":2}]},"b":"v","c":[]}`,
+			read: func(k string) bool { return k == "b" },
+			want: []member{{"b", "v"}},
+		},
+		{
+			name:    "not object",
+			b:       `[]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			b:       `{1:1}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing name separator",
+			b:       `{"a" 1}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid skipped value",
+			b:       `{"a":[1,}`,
+			read:    func(string) bool { return false },
+			wantErr: true,
+		},
+		{
+			name:    "trailing comma",
+			b:       `{"a":1,}`,
+			want:    []member{{"a", 1.0}},
+			wantErr: true,
+		},
+		{
+			name:    "missing separator",
+			b:       `{"a":1 "b":2}`,
+			want:    []member{{"a", 1.0}},
+			wantErr: true,
+		},
+		{
+			name:    "unterminated",
+			b:       `{"a":1`,
+			want:    []member{{"a", 1.0}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParser(strings.NewReader(tt.b))
+
+			var got []member
+			for k, p := range pa.IterObject() {
+				if tt.read != nil && !tt.read(k) {
+					continue
+				}
+				v, err := p.ParseValue()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, member{k, v})
+			}
+			gotErr := pa.IterErr()
+
+			if (gotErr != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", gotErr, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !pa.lx.ExpectEOF() {
+				t.Errorf("expect EOF")
+			}
+		})
+	}
+}
+
+func TestParser_IterObject_Break(t *testing.T) {
+	t.Parallel()
+
+	pa := NewParser(strings.NewReader(`{"a":1,"b":2}`))
+
+	for k := range pa.IterObject() {
+		if k != "a" {
+			t.Errorf("got key %q, want a", k)
+		}
+		break
+	}
+	if err := pa.IterErr(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The value is not skipped.
+	if pa.lx.NextTokenType() != TokenTypeNumber {
+		t.Errorf("expect number")
+	}
+}
+
+func TestParser_IterObject_PartiallyRead(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       string
+		json5   bool
+		read    func(p *Parser) bool
+		wantErr bool
+	}{
+		{
+			name: "begin object only",
+			b:    `{"a":{"x":1},"b":2}`,
+			read: func(p *Parser) bool { return p.Lexer().ExpectBeginObject() },
+			// The rest of {"x":1} is not taken for the members.
+			wantErr: true,
+		},
+		{
+			name:    "nested value only",
+			b:       `{"a":[[1],[2]],"b":2}`,
+			read:    func(p *Parser) bool { return p.Lexer().ExpectBeginArray() && p.skipValue() == nil },
+			wantErr: true,
+		},
+		{
+			name: "whole value",
+			b:    `{"a":{"x":"]}","y":[{}]},"b":2}`,
+			read: func(p *Parser) bool { _, err := p.ParseValue(); return err == nil },
+		},
+		{
+			name:  "whole value with comments",
+			b:     `{a: [1, /* ] */ 2, // ]` + "\n" + `], b: 2}`,
+			json5: true,
+			read:  func(p *Parser) bool { _, err := p.ParseValue(); return err == nil },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParserWithOptions(strings.NewReader(tt.b), ParserOptions{JSON5: tt.json5})
+
+			var keys []string
+			for k, p := range pa.IterObject() {
+				keys = append(keys, k)
+				if k == "a" && !tt.read(p) {
+					t.Fatal("read error")
+				}
+			}
+
+			if err := pa.IterErr(); (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if want := []string{"a"}; !reflect.DeepEqual(keys, want) {
+					t.Errorf("got %q, want %q", keys, want)
+				}
+				return
+			}
+			if want := []string{"a", "b"}; !reflect.DeepEqual(keys, want) {
+				t.Errorf("got %q, want %q", keys, want)
+			}
+			if !pa.lx.ExpectEOF() {
+				t.Errorf("expect EOF")
+			}
+		})
+	}
+}
+
+func TestParser_IterObject_JSON5(t *testing.T) {
+	t.Parallel()
+
+	pa := NewParserWithOptions(
+		strings.NewReader(`{a: [1,], 'b': 2, // c
+}`),
+		ParserOptions{JSON5: true},
+	)
+
+	var keys []string
+	for k := range pa.IterObject() {
+		keys = append(keys, k)
+	}
+	if err := pa.IterErr(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"a", "b"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("got %q, want %q", keys, want)
+	}
+}
+
+func TestParser_IterObject_Many(t *testing.T) {
+	t.Parallel()
+
+	const n = 100000
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := range n {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"k` + strconv.Itoa(i) + `":` + strconv.Itoa(i))
+	}
+	buf.WriteByte('}')
+
+	pa := NewParser(&buf)
+	sum := 0.0
+	for _, p := range pa.IterObject() {
+		v, err := p.ParseFloat64()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += v
+	}
+
+	if want := float64(n * (n - 1) / 2); sum != want {
+		t.Errorf("got %v, want %v", sum, want)
+	}
+}
+
+func BenchmarkParser_IterObject(b *testing.B) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := range 1000 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"k` + strconv.Itoa(i) + `":` + strconv.Itoa(i))
+	}
+	buf.WriteByte('}')
+	bs := buf.Bytes()
+
+	r := bytes.NewReader(bs)
+	pa := NewParser(r)
+
+	for b.Loop() {
+		r.Reset(bs)
+		pa.reset()
+		for _, p := range pa.IterObject() {
+			if _, err := p.ParseFloat64(); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := pa.IterErr(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
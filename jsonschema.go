@@ -0,0 +1,470 @@
+package mocjson
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// JSONSchemaDraft is the $schema of the documents generated by JSONSchema.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema generates a JSON Schema (draft 2020-12) document for t, which
+// accepts the same values as Decode does. The document has the form of the
+// output of ParseValue, so it can be encoded with Encoder.
+//
+// Struct fields follow the json and mocjson tags: fields without omitempty or
+// default are required, aliases are alternative properties, defaults are
+// "default", and the validation options are the corresponding keywords. Named
+// struct types are put in "$defs". A nocase field requires a property which
+// matches it case-insensitively, but two such properties, which Decode
+// rejects as duplicate keys, are not rejected in the document, since JSON
+// Schema cannot count the properties which match a pattern.
+//
+// JSON Schema compares numbers by value, not by how they are written, so
+// "integer" accepts 1.0 and 1e2, and so does Decode for integer types. The
+// numbers of ParseValue are float64, so the document and Decode may disagree
+// on the numbers which float64 cannot represent exactly, such as
+// 1.0000000000000001. For the same reason, the range of 64-bit integers is
+// not in the document.
+func JSONSchema(t reflect.Type) (map[string]any, error) {
+	g := schemaGenerator{
+		defs:  make(map[string]any),
+		names: make(map[reflect.Type]string),
+	}
+
+	s, err := g.generate(t)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string]any{"$schema": JSONSchemaDraft}
+	maps.Copy(ret, s)
+	if len(g.defs) > 0 {
+		ret["$defs"] = g.defs
+	}
+
+	return ret, nil
+}
+
+type schemaGenerator struct {
+	defs  map[string]any
+	names map[reflect.Type]string
+}
+
+func (g *schemaGenerator) generate(t reflect.Type) (map[string]any, error) {
+	switch {
+	case reflect.PointerTo(t).Implements(unmarshalerType):
+		// It decodes itself, so anything may be valid.
+		return map[string]any{}, nil
+
+	case t == rawValueType:
+		return map[string]any{}, nil
+
+	case t == ratType:
+		return map[string]any{"type": "number"}, nil
+
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}, nil
+
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return map[string]any{"type": "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s := map[string]any{"type": "integer"}
+		if t.Bits() <= 32 {
+			s["minimum"] = -math.Ldexp(1, t.Bits()-1)
+			s["maximum"] = math.Ldexp(1, t.Bits()-1) - 1
+		}
+		return s, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		s := map[string]any{"type": "integer", "minimum": 0.0}
+		if t.Bits() <= 32 {
+			s["maximum"] = math.Ldexp(1, t.Bits()) - 1
+		}
+		return s, nil
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return map[string]any{}, nil
+		}
+		u, ok := lookupUnion(t)
+		if !ok {
+			return nil, fmt.Errorf("unsupported interface type: %v", t)
+		}
+		return g.generateUnion(u)
+
+	case reflect.Pointer:
+		s, err := g.generate(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(s), nil
+
+	case reflect.Slice:
+		items, err := g.generate(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": []any{"array", "null"}, "items": items}, nil
+
+	case reflect.Array:
+		items, err := g.generate(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		n := float64(t.Len())
+		return map[string]any{
+			"type":     "array",
+			"items":    items,
+			"minItems": n,
+			"maxItems": n,
+		}, nil
+
+	case reflect.Map:
+		return g.generateMap(t)
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.generateStruct(t, "", "")
+		}
+		return g.generateRef(t)
+	}
+
+	return nil, fmt.Errorf("unsupported type: %v", t)
+}
+
+// nullable returns the schema which also accepts null.
+func nullable(s map[string]any) map[string]any {
+	return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+}
+
+func (g *schemaGenerator) generateMap(t reflect.Type) (map[string]any, error) {
+	if !isDecodableMapKey(t.Key()) {
+		return nil, fmt.Errorf("unsupported map key type: %v", t.Key())
+	}
+
+	values, err := g.generate(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	s := map[string]any{"type": []any{"object", "null"}, "additionalProperties": values}
+
+	if !reflect.PointerTo(t.Key()).Implements(textUnmarshalerType) {
+		switch t.Key().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s["propertyNames"] = map[string]any{"pattern": `^[+-]?[0-9]+$`}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Uintptr:
+			s["propertyNames"] = map[string]any{"pattern": `^[0-9]+$`}
+		}
+	}
+
+	return s, nil
+}
+
+// generateRef puts the schema of the named struct type t in $defs, and returns
+// the reference to it.
+func (g *schemaGenerator) generateRef(t reflect.Type) (map[string]any, error) {
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		for i := 2; slices.Contains(slices.Collect(maps.Values(g.names)), name); i++ {
+			name = fmt.Sprintf("%s%d", t.Name(), i)
+		}
+		g.names[t] = name
+
+		s, err := g.generateStruct(t, "", "")
+		if err != nil {
+			return nil, err
+		}
+		g.defs[name] = s
+	}
+
+	return map[string]any{"$ref": "#/$defs/" + name}, nil
+}
+
+func (g *schemaGenerator) generateUnion(u *union) (map[string]any, error) {
+	var variants []any
+
+	for _, value := range slices.Sorted(maps.Keys(u.variants)) {
+		t := indirectType(u.variants[value])
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("unsupported variant type: %v", u.variants[value])
+		}
+
+		// The variants are inlined since they have the discriminator.
+		s, err := g.generateStruct(t, u.key, value)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, s)
+	}
+
+	return map[string]any{"oneOf": variants}, nil
+}
+
+// generateStruct generates the schema of the struct type t. If key is not
+// empty, the object also has the discriminator key with the value.
+func (g *schemaGenerator) generateStruct(
+	t reflect.Type,
+	key, value string,
+) (map[string]any, error) {
+	si, err := cachedStructInfo(t)
+	if err != nil {
+		return nil, err
+	}
+
+	props := make(map[string]any)
+	patternProps := make(map[string]any)
+	var (
+		required []any
+		allOf    []any
+	)
+
+	if key != "" {
+		if _, ok := si.byName[key]; !ok {
+			props[key] = map[string]any{"const": value}
+			required = append(required, key)
+		}
+	}
+
+	for i := range si.fields {
+		f := &si.fields[i]
+		ft := t.FieldByIndex(f.index).Type
+
+		s, err := g.generateField(ft, f)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.name, err)
+		}
+
+		names := append([]string{f.name}, f.aliases...)
+		for _, n := range names {
+			props[n] = s
+			if f.nocase {
+				patternProps[caseInsensitivePattern(n)] = s
+			}
+		}
+
+		switch {
+		case f.nocase:
+			if f.optional {
+				break
+			}
+			// Some property matches one of the names case-insensitively.
+			patterns := make([]string, len(names))
+			for i, n := range names {
+				patterns[i] = caseInsensitivePattern(n)
+			}
+			allOf = append(allOf, map[string]any{
+				"not": map[string]any{
+					"propertyNames": map[string]any{
+						"not": map[string]any{"pattern": strings.Join(patterns, "|")},
+					},
+				},
+			})
+		case len(names) == 1:
+			if !f.optional {
+				required = append(required, f.name)
+			}
+		default:
+			// Exactly one of the names, or none of them if optional.
+			var oneOf []any
+			for _, n := range names {
+				oneOf = append(oneOf, map[string]any{"required": []any{n}})
+			}
+			if f.optional {
+				oneOf = append(
+					oneOf,
+					map[string]any{"not": map[string]any{"anyOf": slices.Clone(oneOf)}},
+				)
+			}
+			allOf = append(allOf, map[string]any{"oneOf": oneOf})
+		}
+	}
+
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(patternProps) > 0 {
+		s["patternProperties"] = patternProps
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	if len(allOf) > 0 {
+		s["allOf"] = allOf
+	}
+
+	return s, nil
+}
+
+func (g *schemaGenerator) generateField(t reflect.Type, f *fieldInfo) (map[string]any, error) {
+	nullCount := 0
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullCount++
+	}
+
+	var (
+		s   map[string]any
+		err error
+	)
+
+	switch {
+	case f.duration:
+		s = map[string]any{"type": []any{"string", "integer"}}
+
+	case f.timeLayout != "":
+		if _, ok := epochUnits[f.timeLayout]; ok {
+			s = map[string]any{"anyOf": []any{
+				map[string]any{"type": "number"},
+				map[string]any{"type": "string", "pattern": `^-?[0-9]`},
+			}}
+		} else {
+			s = map[string]any{"type": "string"}
+		}
+
+	default:
+		s, err = g.generate(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.rules != nil {
+		s = f.rules.schema(t, s)
+	}
+
+	if nullCount > 0 && (f.rules == nil || !f.rules.nonempty) {
+		s = nullable(s)
+	}
+
+	if f.defaultValue != nil {
+		pa := NewParser(bytes.NewReader(f.defaultValue))
+		v, err := pa.ParseValue()
+		if err != nil {
+			return nil, fmt.Errorf("parse default error: %w", err)
+		}
+		s = maps.Clone(s)
+		s["default"] = v
+	}
+
+	return s, nil
+}
+
+// schema adds the keywords of the rules to s, the schema of t.
+func (r *rules) schema(t reflect.Type, s map[string]any) map[string]any {
+	// s may be shared with $defs, so the keywords are added with allOf.
+	if _, ok := s["$ref"]; ok {
+		s = map[string]any{"allOf": []any{s}}
+	}
+	s = maps.Clone(s)
+
+	if r.hasMin {
+		s["minimum"] = r.min
+	}
+	if r.hasMax {
+		s["maximum"] = r.max
+	}
+
+	minKey, maxKey := "minLength", "maxLength"
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		minKey, maxKey = "minItems", "maxItems"
+	case reflect.Map:
+		minKey, maxKey = "minProperties", "maxProperties"
+	}
+
+	if r.hasMinLength {
+		s[minKey] = float64(r.minLength)
+	}
+	if r.hasMaxLength {
+		s[maxKey] = float64(r.maxLength)
+	}
+	if r.nonempty {
+		if n, ok := s[minKey].(float64); !ok || n < 1 {
+			s[minKey] = 1.0
+		}
+		if tt, ok := s["type"].([]any); ok {
+			// A nil slice or map is empty.
+			s["type"] = tt[0]
+		}
+	}
+
+	if r.pattern != nil {
+		s["pattern"] = r.pattern.String()
+	}
+
+	if r.enum != nil {
+		var enum []any
+		for _, e := range r.enum {
+			if t.Kind() == reflect.String {
+				enum = append(enum, e)
+				continue
+			}
+			pa := NewParser(strings.NewReader(e))
+			if v, err := pa.ParseValue(); err == nil {
+				enum = append(enum, v)
+			}
+		}
+		s["enum"] = enum
+	}
+
+	return s
+}
+
+// caseInsensitivePattern returns the pattern which matches s case-insensitively.
+func caseInsensitivePattern(s string) string {
+	var b strings.Builder
+	b.WriteByte('^')
+
+	for len(s) > 0 {
+		r, n := utf8.DecodeRuneInString(s)
+		s = s[n:]
+
+		upper, lower := unicode.ToUpper(r), unicode.ToLower(r)
+		if upper == lower {
+			b.WriteString(regexpQuoteRune(r))
+			continue
+		}
+
+		b.WriteByte('[')
+		b.WriteRune(upper)
+		b.WriteRune(lower)
+		b.WriteByte(']')
+	}
+
+	b.WriteByte('$')
+	return b.String()
+}
+
+func regexpQuoteRune(r rune) string {
+	if strings.ContainsRune(`\.+*?()|[]{}^$`, r) {
+		return `\` + string(r)
+	}
+
+	return string(r)
+}
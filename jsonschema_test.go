@@ -0,0 +1,208 @@
+// The tests are in the external package since they use the schema package,
+// which imports mocjson.
+package mocjson_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/high-moctane/mocjson-go"
+	"github.com/high-moctane/mocjson-go/schema"
+)
+
+type jsonSchemaTestItem struct {
+	Name  string   `json:"name"            mocjson:"minLength=1,pattern=^[a-z]+$"`
+	Count uint8    `json:"count,omitempty"`
+	Tags  []string `json:"tags"            mocjson:"nonempty"`
+}
+
+type jsonSchemaTestObject struct {
+	ID       int64                         `json:"id"       mocjson:"alias=uid,min=1"`
+	Email    string                        `json:"email"    mocjson:"nocase"`
+	Level    *int                          `json:"level"    mocjson:"enum=1|2|3"`
+	Mode     string                        `json:"mode"     mocjson:"enum=a|b,default=\"a\""`
+	Items    []jsonSchemaTestItem          `json:"items"`
+	Next     *jsonSchemaTestObject         `json:"next,omitempty"`
+	Labels   map[string]float64            `json:"labels,omitempty"`
+	Counts   map[uint16]jsonSchemaTestItem `json:"counts,omitempty"`
+	Point    [2]float32                    `json:"point,omitempty"`
+	Created  time.Time                     `json:"created,omitempty,time=unixmilli"`
+	Timeout  time.Duration                 `json:"timeout,omitempty,duration"`
+	Event    mocjson.SampleEvent           `json:"event,omitempty"`
+	Any      any                           `json:"any,omitempty"`
+	Raw      mocjson.RawValue              `json:"raw,omitempty"`
+	Ignored  string                        `json:"-"`
+	Untagged bool                          `json:",omitempty"`
+}
+
+// TestJSONSchema checks that the generated schema accepts exactly the values
+// which Decode accepts, except for the keys of a nocase field which differ
+// only in case, such as "email" and "EMAIL" in one object. See JSONSchema.
+func TestJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	doc, err := mocjson.JSONSchema(reflect.TypeFor[jsonSchemaTestObject]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	en := mocjson.NewEncoder(&buf)
+	if err := en.Encode(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := schema.Compile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const valid = `"id":1,"email":"e","level":null,"items":[{"name":"a","tags":["t"]}]`
+
+	tests := []struct {
+		name string
+		b    string
+	}{
+		{"valid", `{` + valid + `}`},
+		{"alias", `{"uid":1,"email":"e","level":2,"items":[]}`},
+		{"name and alias", `{"uid":1,` + valid + `}`},
+		{"missing id", `{"email":"e","level":null,"items":[]}`},
+		{"id less than min", `{"id":0,"email":"e","level":null,"items":[]}`},
+		{"nocase", `{"id":1,"EMAIL":"e","level":null,"items":[]}`},
+		{"missing nocase", `{"id":1,"level":null,"items":[]}`},
+		{"nocase prefix", `{"id":1,"EMAILS":"e","level":null,"items":[]}`},
+		{"unknown key", `{"unknown":1,` + valid + `}`},
+		{"level not in enum", `{"id":1,"email":"e","level":4,"items":[]}`},
+		{"missing level", `{"id":1,"email":"e","items":[]}`},
+		{"mode", `{"mode":"b",` + valid + `}`},
+		{"mode not in enum", `{"mode":"c",` + valid + `}`},
+		{"null items", `{"id":1,"email":"e","level":null,"items":null}`},
+		{"item pattern", `{"id":1,"email":"e","level":null,"items":[{"name":"A","tags":["t"]}]}`},
+		{"item empty tags", `{"id":1,"email":"e","level":null,"items":[{"name":"a","tags":[]}]}`},
+		{"item null tags", `{"id":1,"email":"e","level":null,"items":[{"name":"a","tags":null}]}`},
+		{
+			"item count overflow",
+			`{"id":1,"email":"e","level":null,"items":[{"name":"a","tags":["t"],"count":256}]}`,
+		},
+		{"next", `{"next":{` + valid + `},` + valid + `}`},
+		{"invalid next", `{"next":{"id":1},` + valid + `}`},
+		{"labels", `{"labels":{"a":1.5},` + valid + `}`},
+		{"counts", `{"counts":{"1":{"name":"a","tags":["t"]}},` + valid + `}`},
+		{"invalid counts key", `{"counts":{"a":{"name":"a","tags":["t"]}},` + valid + `}`},
+		{"point", `{"point":[1,2],` + valid + `}`},
+		{"short point", `{"point":[1],` + valid + `}`},
+		{"created", `{"created":1136214245123,` + valid + `}`},
+		{"created string", `{"created":"1136214245123",` + valid + `}`},
+		{"timeout", `{"timeout":"1s",` + valid + `}`},
+		{"timeout number", `{"timeout":1000,` + valid + `}`},
+		{"event", `{"event":{"kind":"created","id":"1","name":"n"},` + valid + `}`},
+		{
+			"event with alias",
+			`{"event":{"kind":"created","event_id":"1","name":"n"},` + valid + `}`,
+		},
+		{"unknown event", `{"event":{"kind":"updated","id":"1"},` + valid + `}`},
+		{
+			"deleted event reason",
+			`{"event":{"kind":"deleted","id":"1","reason":"lost"},` + valid + `}`,
+		},
+		{"any and raw", `{"any":[1,{}],"raw":{"a":null},` + valid + `}`},
+		{"untagged", `{"Untagged":true,` + valid + `}`},
+		{"ignored", `{"Ignored":"x",` + valid + `}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := mocjson.NewParser(strings.NewReader(tt.b))
+			var v jsonSchemaTestObject
+			decodeErr := pa.Decode(&v)
+
+			pa = mocjson.NewParser(strings.NewReader(tt.b))
+			schemaErr := s.ValidateParser(&pa)
+
+			if (decodeErr == nil) != (schemaErr == nil) {
+				t.Errorf("Decode error %v, but schema error %v", decodeErr, schemaErr)
+			}
+		})
+	}
+}
+
+// TestJSONSchema_IntegerLiterals checks that the schema and Decode agree on
+// integers written with a fraction or an exponent.
+func TestJSONSchema_IntegerLiterals(t *testing.T) {
+	t.Parallel()
+
+	doc, err := mocjson.JSONSchema(reflect.TypeFor[int32]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	en := mocjson.NewEncoder(&buf)
+	if err := en.Encode(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := schema.Compile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		b     string
+		valid bool
+	}{
+		{"100", true},
+		{"100.0", true},
+		{"1e2", true},
+		{"-2.147483648E9", true},
+		{"100.5", false},
+		{"1e-2", false},
+		{"2147483648", false},
+		{"2.147483648e9", false},
+	}
+
+	for _, tt := range tests {
+		pa := mocjson.NewParser(strings.NewReader(tt.b))
+		var v int32
+		decodeErr := pa.Decode(&v)
+
+		pa = mocjson.NewParser(strings.NewReader(tt.b))
+		schemaErr := s.ValidateParser(&pa)
+
+		if (schemaErr == nil) != tt.valid {
+			t.Errorf("%s: got schema error %v, want valid %v", tt.b, schemaErr, tt.valid)
+		}
+		if (decodeErr == nil) != tt.valid {
+			t.Errorf("%s: got Decode error %v, want valid %v", tt.b, decodeErr, tt.valid)
+		}
+	}
+}
+
+func TestJSONSchema_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	for _, typ := range []reflect.Type{
+		reflect.TypeFor[func()](),
+		reflect.TypeFor[map[bool]int](),
+		reflect.TypeFor[interface{ Method() }](),
+	} {
+		if _, err := mocjson.JSONSchema(typ); err == nil {
+			t.Errorf("%v: got nil, want error", typ)
+		}
+	}
+}
+
+func BenchmarkJSONSchema(b *testing.B) {
+	typ := reflect.TypeFor[jsonSchemaTestObject]()
+
+	for b.Loop() {
+		if _, err := mocjson.JSONSchema(typ); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package mocjson
+
+// KeyHash hashes the object key k with the seed. Generated parsers dispatch
+// keys with a perfect hash table built by FindKeyHashSeed, so that the keys
+// are neither materialised as strings nor compared one by one.
+func KeyHash(k []byte, seed uint32) uint32 {
+	h := 2166136261 ^ seed
+	for _, b := range k {
+		h ^= uint32(b)
+		h *= 16777619
+	}
+
+	// The low bits of FNV-1a depend only on the low bits of the input, so
+	// they are mixed with the high bits.
+	h ^= h >> 16
+	h *= 0x7feb352d
+	h ^= h >> 15
+
+	return h
+}
+
+// FindKeyHashSeed finds the seed with which KeyHash(k, seed)&(size-1) is
+// distinct for all keys. size must be a power of two.
+func FindKeyHashSeed(keys []string, size int) (uint32, bool) {
+	if size <= 0 || size&(size-1) != 0 || len(keys) > size {
+		return 0, false
+	}
+
+	used := make([]bool, size)
+
+Seed:
+	for seed := range uint32(1 << 20) {
+		clear(used)
+
+		for _, k := range keys {
+			slot := KeyHash([]byte(k), seed) & uint32(size-1)
+			if used[slot] {
+				continue Seed
+			}
+			used[slot] = true
+		}
+
+		return seed, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,105 @@
+package mocjson
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFindKeyHashSeed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		keys   []string
+		size   int
+		wantOK bool
+	}{
+		{
+			name:   "ok",
+			keys:   []string{"boolean", "float64", "string", "object", "array", "any"},
+			size:   8,
+			wantOK: true,
+		},
+		{
+			name:   "ok: empty key",
+			keys:   []string{"", "a", "b"},
+			size:   4,
+			wantOK: true,
+		},
+		{
+			name:   "ng: too many keys",
+			keys:   []string{"a", "b", "c"},
+			size:   2,
+			wantOK: false,
+		},
+		{
+			name:   "ng: size is not a power of two",
+			keys:   []string{"a", "b"},
+			size:   3,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			seed, ok := FindKeyHashSeed(tt.keys, tt.size)
+			if ok != tt.wantOK {
+				t.Fatalf("gotOK %v, wantOK %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+
+			used := make(map[uint32]string)
+			for _, k := range tt.keys {
+				slot := KeyHash([]byte(k), seed) & uint32(tt.size-1)
+				if u, ok := used[slot]; ok {
+					t.Errorf("%q and %q collide at %d", u, k, slot)
+				}
+				used[slot] = k
+			}
+		})
+	}
+}
+
+// TestKeyHashTables checks the perfect hash tables of the generated parsers.
+func TestKeyHashTables(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		seed   uint32
+		keys   []string
+		fields []uint8
+	}{
+		{sampleObject1KeySeed, sampleObject1Keys[:], sampleObject1Fields[:]},
+		{sampleObject2KeySeed, sampleObject2Keys[:], sampleObject2Fields[:]},
+		{sampleCreatedEventKeySeed, sampleCreatedEventKeys[:], sampleCreatedEventFields[:]},
+		{sampleDeletedEventKeySeed, sampleDeletedEventKeys[:], sampleDeletedEventFields[:]},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+
+			for slot, k := range tt.keys {
+				if tt.fields[slot] == 0 {
+					continue
+				}
+				got := KeyHash([]byte(k), tt.seed) & uint32(len(tt.keys)-1)
+				if got != uint32(slot) {
+					t.Errorf("%q: got slot %d, want %d", k, got, slot)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkKeyHash(b *testing.B) {
+	k := []byte("object2_array")
+
+	for b.Loop() {
+		KeyHash(k, sampleObject1KeySeed)
+	}
+}
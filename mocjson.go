@@ -2,6 +2,7 @@ package mocjson
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf16"
 	"unicode/utf8"
 )
@@ -20,10 +22,24 @@ const (
 	ScannerBufRetainSize = 64
 )
 
+// byteOrderMark is the UTF-8 BOM, which the Scanner skips at the beginning of
+// the input.
+const byteOrderMark = "\ufeff"
+
 type Scanner struct {
 	r   io.Reader
 	buf []byte
 	err error
+
+	// off is the number of the skipped bytes.
+	off int64
+
+	// ctx is checked before the buffer is refilled if it is not nil.
+	ctx context.Context
+
+	// rec holds the skipped bytes while recDepth > 0.
+	rec      []byte
+	recDepth int
 }
 
 func NewScanner(r io.Reader) Scanner {
@@ -34,10 +50,27 @@ func NewScanner(r io.Reader) Scanner {
 func (sc *Scanner) reset() {
 	sc.buf = nil
 	sc.err = nil
+	sc.off = 0
+	sc.rec = nil
+	sc.recDepth = 0
+}
+
+// resetAt makes sc read r as the input from the offset off, e.g. a line of
+// JSON Lines. The BOM is skipped only at the offset 0, the beginning of the
+// input.
+func (sc *Scanner) resetAt(r io.Reader, off int64) {
+	*sc = Scanner{r: r, off: off}
 }
 
 func (sc *Scanner) Load() bool {
+	if sc.err == nil && len(sc.buf) < ScannerBufRetainSize && sc.ctx != nil {
+		// The error stops the reads as the errors of the reader do.
+		sc.err = sc.ctx.Err()
+	}
+
 	if sc.err == nil && len(sc.buf) < ScannerBufRetainSize {
+		first := sc.off == 0 && len(sc.buf) == 0
+
 		b := make([]byte, ScannerBufSize)
 		n := copy(b, sc.buf)
 
@@ -48,6 +81,11 @@ func (sc *Scanner) Load() bool {
 		}
 
 		sc.buf = b[:n]
+
+		if first && bytes.HasPrefix(sc.buf, []byte(byteOrderMark)) {
+			sc.buf = sc.buf[len(byteOrderMark):]
+			sc.off += int64(len(byteOrderMark))
+		}
 	}
 
 	return len(sc.buf) != 0
@@ -58,7 +96,30 @@ func (sc *Scanner) Err() error {
 }
 
 func (sc *Scanner) Skip(n int) {
+	if sc.recDepth > 0 {
+		sc.rec = append(sc.rec, sc.buf[:n]...)
+	}
 	sc.buf = sc.buf[n:]
+	sc.off += int64(n)
+}
+
+// startRecording starts recording the skipped bytes. It returns a mark which
+// must be passed to stopRecording. Recordings can be nested.
+func (sc *Scanner) startRecording() int {
+	sc.recDepth++
+	return len(sc.rec)
+}
+
+// stopRecording returns a copy of the bytes skipped since the mark.
+func (sc *Scanner) stopRecording(mark int) []byte {
+	ret := bytes.Clone(sc.rec[mark:])
+
+	sc.recDepth--
+	if sc.recDepth == 0 {
+		sc.rec = sc.rec[:0]
+	}
+
+	return ret
 }
 
 func (sc *Scanner) Peek() byte {
@@ -171,6 +232,9 @@ const (
 
 type Lexer struct {
 	sc Scanner
+
+	// json5 enables the JSON5 syntax. See ParserOptions.
+	json5 bool
 }
 
 func NewLexer(r io.Reader) Lexer {
@@ -186,6 +250,9 @@ func (lx *Lexer) skipWhiteSpaces() {
 	for lx.sc.Load() {
 		n := lx.sc.CountWhiteSpace()
 		if n == 0 {
+			if lx.json5 && lx.skipComment() {
+				continue
+			}
 			break
 		}
 
@@ -193,6 +260,53 @@ func (lx *Lexer) skipWhiteSpaces() {
 	}
 }
 
+// skipComment skips the comment which follows, and reports whether there is
+// one. An unterminated block comment stops the Scanner with an error.
+func (lx *Lexer) skipComment() bool {
+	if lx.sc.BufferedLen() < 2 || lx.sc.Peek() != '/' {
+		return false
+	}
+
+	switch lx.sc.PeekN(2)[1] {
+	case '/':
+		lx.sc.Skip(2)
+
+		for lx.sc.Load() {
+			if i := bytes.IndexByte(lx.sc.buf, '\n'); i >= 0 {
+				lx.sc.Skip(i + 1)
+				break
+			}
+			lx.sc.Skip(lx.sc.BufferedLen())
+		}
+
+		return true
+
+	case '*':
+		lx.sc.Skip(2)
+
+		for lx.sc.Load() {
+			if i := bytes.Index(lx.sc.buf, []byte("*/")); i >= 0 {
+				lx.sc.Skip(i + 2)
+				return true
+			}
+
+			// The last '*' may be followed by '/' after the refill.
+			n := lx.sc.BufferedLen()
+			if n > 1 && lx.sc.buf[n-1] == '*' {
+				n--
+			}
+			lx.sc.Skip(n)
+		}
+
+		if lx.sc.err == io.EOF {
+			lx.sc.err = errors.New("unterminated comment")
+		}
+		return true
+	}
+
+	return false
+}
+
 func (lx *Lexer) NextTokenType() TokenType {
 	lx.skipWhiteSpaces()
 
@@ -221,9 +335,24 @@ func (lx *Lexer) NextTokenType() TokenType {
 		return TokenTypeNumber
 	case '"':
 		return TokenTypeString
-	default:
-		return TokenTypeInvalid
 	}
+
+	if lx.json5 {
+		switch lx.sc.Peek() {
+		case '+', 'I', 'N':
+			return TokenTypeNumber
+		case '\'':
+			return TokenTypeString
+		}
+	}
+
+	return TokenTypeInvalid
+}
+
+// TrailingComma reports whether end follows the value separator which has
+// just been read. Trailing commas are allowed only in JSON5 mode.
+func (lx *Lexer) TrailingComma(end TokenType) bool {
+	return lx.json5 && lx.NextTokenType() == end
 }
 
 func (lx *Lexer) ExpectEOF() bool {
@@ -428,7 +557,7 @@ func (lx *Lexer) ExpectNumberBytes() ([]byte, bool) {
 		return nil, false
 	}
 
-	if lx.sc.Peek() == '-' {
+	if lx.sc.Peek() == '-' || lx.json5 && lx.sc.Peek() == '+' {
 		ret = append(ret, lx.sc.PeekN(1)...)
 		lx.sc.Skip(1)
 	}
@@ -438,6 +567,12 @@ func (lx *Lexer) ExpectNumberBytes() ([]byte, bool) {
 		return nil, false
 	}
 
+	if lx.json5 {
+		if b, ok := lx.expectJSON5Number(); ok {
+			return append(ret, b...), true
+		}
+	}
+
 	digitLen := lx.sc.CountDigit()
 	if digitLen == 0 {
 		return nil, false
@@ -541,6 +676,154 @@ func (lx *Lexer) ExpectNumberBytes() ([]byte, bool) {
 	return ret, true
 }
 
+// expectJSON5Number reads the numbers which only JSON5 has after the sign:
+// Infinity, NaN and hexadecimal integers. It reads nothing for the others.
+func (lx *Lexer) expectJSON5Number() ([]byte, bool) {
+	for _, word := range []string{"Infinity", "NaN"} {
+		if lx.sc.BufferedLen() >= len(word) && string(lx.sc.PeekN(len(word))) == word {
+			lx.sc.Skip(len(word))
+			return []byte(word), true
+		}
+	}
+
+	if lx.sc.BufferedLen() < 3 {
+		return nil, false
+	}
+	if b := lx.sc.PeekN(3); b[0] != '0' || b[1] != 'x' && b[1] != 'X' ||
+		!strings.ContainsRune("0123456789abcdefABCDEF", rune(b[2])) {
+		return nil, false
+	}
+
+	ret := bytes.Clone(lx.sc.PeekN(2))
+	lx.sc.Skip(2)
+
+	for lx.sc.Load() {
+		n := lx.sc.CountHex()
+		if n == 0 {
+			break
+		}
+		ret = append(ret, lx.sc.PeekN(n)...)
+		lx.sc.Skip(n)
+	}
+
+	return ret, true
+}
+
+// floatSyntax returns the number b in the syntax of strconv.ParseFloat.
+func (lx *Lexer) floatSyntax(b []byte) string {
+	s := string(b)
+	if !lx.json5 {
+		return s
+	}
+
+	s = strings.TrimPrefix(s, "+")
+	if s == "-NaN" {
+		return "NaN"
+	}
+	if isHexNumber(s) {
+		// ParseFloat needs the exponent for hexadecimal numbers.
+		s += "p0"
+	}
+
+	return s
+}
+
+// intSyntax returns the number b and the base in the syntax of
+// strconv.ParseInt and strconv.ParseUint.
+func (lx *Lexer) intSyntax(b []byte) (string, int) {
+	s := string(b)
+	if !lx.json5 {
+		return s, 10
+	}
+
+	// The base prefix of hexadecimal numbers is accepted with the base 0. The
+	// others cannot be read since they are not valid JSON5.
+	return strings.TrimPrefix(s, "+"), 0
+}
+
+// parseInt parses the number b as an integer of the bit size. A number with a
+// fraction or an exponent is accepted if its value is an integer, e.g. 1.0 and
+// 1e2, since JSON does not distinguish them from 1 and 100.
+func (lx *Lexer) parseInt(b []byte, bitSize int) (int64, error) {
+	s, base := lx.intSyntax(b)
+
+	v, err := strconv.ParseInt(s, base, bitSize)
+	if errors.Is(err, strconv.ErrSyntax) && !isHexNumber(s) {
+		if lit, ok := intLiteral(s); ok {
+			return strconv.ParseInt(lit, 10, bitSize)
+		}
+	}
+
+	return v, err
+}
+
+// parseUint is like parseInt, but for an unsigned integer.
+func (lx *Lexer) parseUint(b []byte, bitSize int) (uint64, error) {
+	s, base := lx.intSyntax(b)
+
+	v, err := strconv.ParseUint(s, base, bitSize)
+	if errors.Is(err, strconv.ErrSyntax) && !isHexNumber(s) {
+		if lit, ok := intLiteral(s); ok {
+			return strconv.ParseUint(lit, 10, bitSize)
+		}
+	}
+
+	return v, err
+}
+
+// intLiteral rewrites the decimal number s with a fraction or an exponent as
+// an integer literal, e.g. "1.5e1" as "15", if its value is an integer. The
+// values beyond 64 bits are rewritten as a literal which overflows, so that
+// the exponent does not make a huge literal.
+func intLiteral(s string) (string, bool) {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		n, err := strconv.Atoi(s[i+1:])
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return "", false
+		}
+		// The exponent is clamped so as not to overflow below, which does not
+		// change the result as the number of the digits is far less.
+		exp, s = max(min(n, 1<<40), -1<<40), s[:i]
+	}
+
+	ip, fp, _ := strings.Cut(s, ".")
+	if ip == "" || strings.Trim(ip+fp, "0123456789") != "" {
+		return "", false
+	}
+
+	digits := strings.TrimLeft(ip+fp, "0")
+	if digits == "" {
+		return "0", true
+	}
+	trimmed := strings.TrimRight(digits, "0")
+	exp += len(digits) - len(trimmed) - len(fp)
+	digits = trimmed
+
+	const maxUint64Len = 20
+
+	switch {
+	case exp < 0:
+		return "", false
+	case len(digits)+exp > maxUint64Len:
+		return sign + strings.Repeat("9", maxUint64Len+1), true
+	}
+
+	return sign + digits + strings.Repeat("0", exp), true
+}
+
+func isHexNumber(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	return strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X")
+}
+
+// ExpectString reads a string. In JSON5 mode, the string may be single-quoted,
+// and \' is also an escape.
 func (lx *Lexer) ExpectString() (string, bool) {
 	lx.skipWhiteSpaces()
 
@@ -548,7 +831,8 @@ func (lx *Lexer) ExpectString() (string, bool) {
 		return "", false
 	}
 
-	if lx.sc.Peek() != '"' {
+	quote := lx.sc.Peek()
+	if quote != '"' && (!lx.json5 || quote != '\'') {
 		return "", false
 	}
 	lx.sc.Skip(1)
@@ -561,10 +845,15 @@ func (lx *Lexer) ExpectString() (string, bool) {
 		}
 
 		switch lx.sc.Peek() {
-		case '"':
+		case quote:
 			lx.sc.Skip(1)
 			return b.String(), true
 
+		case '"':
+			// in a single-quoted string
+			b.WriteByte('"')
+			lx.sc.Skip(1)
+
 		case '\\':
 			lx.sc.Skip(1)
 
@@ -576,6 +865,12 @@ func (lx *Lexer) ExpectString() (string, bool) {
 			case '"':
 				b.WriteByte('"')
 				lx.sc.Skip(1)
+			case '\'':
+				if !lx.json5 {
+					return "", false
+				}
+				b.WriteByte('\'')
+				lx.sc.Skip(1)
 			case '\\':
 				b.WriteByte('\\')
 				lx.sc.Skip(1)
@@ -651,6 +946,12 @@ func (lx *Lexer) ExpectString() (string, bool) {
 
 		default:
 			if n := lx.sc.CountUnescapedASCII(); n > 0 {
+				if quote == '\'' {
+					// The closing single quote is unescaped ASCII.
+					if i := bytes.IndexByte(lx.sc.PeekN(n), quote); i >= 0 {
+						n = i
+					}
+				}
 				b.Write(lx.sc.PeekN(n))
 				lx.sc.Skip(n)
 			} else if n := lx.sc.CountMultiByteUTF8(); n > 0 {
@@ -668,6 +969,113 @@ func (lx *Lexer) ExpectString() (string, bool) {
 	}
 }
 
+// ExpectStringBytes is like ExpectString, but it returns the bytes of the
+// string. When the string is ASCII without escapes and is in the buffer, the
+// bytes are returned without copying. The bytes must not be modified.
+func (lx *Lexer) ExpectStringBytes() ([]byte, bool) {
+	lx.skipWhiteSpaces()
+
+	if !lx.sc.Load() {
+		return nil, false
+	}
+
+	if lx.sc.Peek() == '"' {
+		buf := lx.sc.PeekN(lx.sc.BufferedLen())
+	Fast:
+		for i := 1; i < len(buf); i++ {
+			switch b := buf[i]; {
+			case b == '"':
+				lx.sc.Skip(i + 1)
+				return buf[1:i], true
+			case b == '\\' || b < 0x20 || b >= utf8.RuneSelf:
+				break Fast
+			}
+		}
+	}
+
+	s, ok := lx.ExpectString()
+	if !ok {
+		return nil, false
+	}
+
+	return []byte(s), true
+}
+
+// expectKey reads an object key. In JSON5 mode, the key may be an identifier
+// without quotes.
+func (lx *Lexer) expectKey() (string, bool) {
+	if !lx.json5 {
+		return lx.ExpectString()
+	}
+
+	lx.skipWhiteSpaces()
+
+	if !lx.sc.Load() {
+		return "", false
+	}
+
+	if c := lx.sc.Peek(); c == '"' || c == '\'' {
+		return lx.ExpectString()
+	}
+
+	return lx.expectIdentifier()
+}
+
+// ExpectKeyBytes is like ExpectStringBytes, but it reads an object key, which
+// may be an identifier without quotes in JSON5 mode.
+func (lx *Lexer) ExpectKeyBytes() ([]byte, bool) {
+	if !lx.json5 {
+		return lx.ExpectStringBytes()
+	}
+
+	lx.skipWhiteSpaces()
+
+	if !lx.sc.Load() {
+		return nil, false
+	}
+
+	if lx.sc.Peek() == '"' {
+		return lx.ExpectStringBytes()
+	}
+
+	k, ok := lx.expectKey()
+	if !ok {
+		return nil, false
+	}
+
+	return []byte(k), true
+}
+
+// expectIdentifier reads an ECMAScript IdentifierName without escapes.
+func (lx *Lexer) expectIdentifier() (string, bool) {
+	var b strings.Builder
+
+	for lx.sc.Load() {
+		// The buffer holds a whole rune unless it is at EOF.
+		r, n := utf8.DecodeRune(lx.sc.PeekN(lx.sc.BufferedLen()))
+		if !isIdentifierRune(r, b.Len() == 0) {
+			break
+		}
+
+		b.Write(lx.sc.PeekN(n))
+		lx.sc.Skip(n)
+	}
+
+	return b.String(), b.Len() > 0
+}
+
+func isIdentifierRune(r rune, first bool) bool {
+	switch {
+	case r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r):
+		return true
+	case first:
+		return false
+	}
+
+	return unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc) ||
+		r == '\u200c' || r == '\u200d'
+}
+
 func (lx *Lexer) parseUTF16Hex(b []byte) rune {
 	if len(b) != 4 {
 		panic(fmt.Sprintf("invalid hex: %q", b))
@@ -691,17 +1099,53 @@ func (lx *Lexer) parseUTF16Hex(b []byte) rune {
 
 type Parser struct {
 	lx Lexer
+
+	// iterErr is the error which has stopped the last IterObject.
+	iterErr error
 }
 
 func NewParser(r io.Reader) Parser {
 	return Parser{lx: NewLexer(r)}
 }
 
+// ParserOptions configures a Parser. The zero value is strict RFC 8259.
+type ParserOptions struct {
+	// JSON5 accepts the following JSON5 syntax in addition: // and /* */
+	// comments, trailing commas in arrays and objects, single-quoted strings,
+	// identifier keys without quotes, hexadecimal integers, numbers with a
+	// plus sign, Infinity and NaN. The Parse methods, Decode, the iterators
+	// and the code generated by mocjson-gen accept it.
+	JSON5 bool
+
+	// DetectEncoding detects UTF-16 and UTF-32 input from the BOM or from the
+	// pattern of the zero bytes in the first four bytes (RFC 4627), and
+	// transcodes it into UTF-8. The offsets of the Scanner are counted in
+	// UTF-8 then. A UTF-8 BOM is skipped regardless of this option.
+	DetectEncoding bool
+}
+
+func NewParserWithOptions(r io.Reader, opts ParserOptions) Parser {
+	if opts.DetectEncoding {
+		r = &transcodeReader{r: r}
+	}
+
+	pa := NewParser(r)
+	pa.lx.json5 = opts.JSON5
+
+	return pa
+}
+
 // reset is called for testing.
 func (pa *Parser) reset() {
 	pa.lx.reset()
 }
 
+// Lexer returns the underlying Lexer for token level access, e.g. from
+// Unmarshaler implementations.
+func (pa *Parser) Lexer() *Lexer {
+	return &pa.lx
+}
+
 func (pa *Parser) Parse() (any, error) {
 	v, err := pa.ParseValue()
 	if err != nil {
@@ -719,6 +1163,32 @@ func (pa *Parser) Parse() (any, error) {
 	return v, nil
 }
 
+// ParseContext is like Parse, but stops reading the input when ctx is done.
+// The context is checked whenever the Scanner refills its buffer, so a Read
+// in progress is not interrupted. The returned error wraps ctx.Err() then, and
+// the Parser cannot be used any more.
+func (pa *Parser) ParseContext(ctx context.Context) (any, error) {
+	pa.lx.sc.ctx = ctx
+	defer func() { pa.lx.sc.ctx = nil }()
+
+	v, err := pa.Parse()
+	if err != nil {
+		return nil, pa.contextError(ctx, err)
+	}
+
+	return v, nil
+}
+
+// contextError returns the error of ctx if it has stopped the Scanner, or err
+// otherwise.
+func (pa *Parser) contextError(ctx context.Context, err error) error {
+	if cerr := ctx.Err(); cerr != nil && pa.lx.sc.err == cerr {
+		return fmt.Errorf("context error: %w", cerr)
+	}
+
+	return err
+}
+
 func (pa *Parser) ParseValue() (any, error) {
 	var (
 		v   any
@@ -777,6 +1247,10 @@ func (pa *Parser) ParseArray() ([]any, error) {
 
 		case TokenTypeValueSeparator:
 			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndArray) {
+				pa.lx.sc.Skip(1)
+				return ret, nil
+			}
 
 		default:
 			return nil, errors.New("expect value separator or end array")
@@ -818,6 +1292,10 @@ func (pa *Parser) ParseObject() (map[string]any, error) {
 
 		case TokenTypeValueSeparator:
 			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndObject) {
+				pa.lx.sc.Skip(1)
+				return ret, nil
+			}
 
 		default:
 			return nil, errors.New("expect value separator or end object")
@@ -836,7 +1314,7 @@ func (pa *Parser) ParseObject() (map[string]any, error) {
 }
 
 func (pa *Parser) parseObjectKeyValuePair() (string, any, error) {
-	k, err := pa.ParseString()
+	k, err := pa.parseKey()
 	if err != nil {
 		return "", nil, fmt.Errorf("parse key error: %w", err)
 	}
@@ -876,13 +1354,29 @@ func (pa *Parser) ParseRat() (*big.Rat, error) {
 	return r, nil
 }
 
+// ParseInt64 parses a number as Decode does for int64: a number with a
+// fraction or an exponent is accepted if its value is an integer.
+func (pa *Parser) ParseInt64() (int64, error) {
+	b, ok := pa.lx.ExpectNumberBytes()
+	if !ok {
+		return 0, errors.New("expect int64")
+	}
+
+	v, err := pa.lx.parseInt(b, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse int64 error: %w", err)
+	}
+
+	return v, nil
+}
+
 func (pa *Parser) ParseFloat64() (float64, error) {
 	b, ok := pa.lx.ExpectNumberBytes()
 	if !ok {
 		return 0, errors.New("expect float64")
 	}
 
-	f, err := strconv.ParseFloat(string(b), 64)
+	f, err := strconv.ParseFloat(pa.lx.floatSyntax(b), 64)
 	if err != nil {
 		if errors.Is(err, strconv.ErrRange) {
 			// TODO(high-moctane): strict option
@@ -894,6 +1388,16 @@ func (pa *Parser) ParseFloat64() (float64, error) {
 	return f, nil
 }
 
+// parseKey parses an object key, which may be an identifier in JSON5 mode.
+func (pa *Parser) parseKey() (string, error) {
+	k, ok := pa.lx.expectKey()
+	if !ok {
+		return "", errors.New("expect string")
+	}
+
+	return k, nil
+}
+
 func (pa *Parser) ParseString() (string, error) {
 	s, ok := pa.lx.ExpectString()
 	if !ok {
@@ -911,131 +1415,392 @@ func (pa *Parser) ParseNull() (any, error) {
 	return nil, nil
 }
 
-func (pa *Parser) ParseSampleObject1() (SampleObject1, error) {
-	if !pa.lx.ExpectBeginObject() {
-		return SampleObject1{}, errors.New("expect begin object")
+// RawValue is the raw bytes of a JSON value. It is validated but not decoded.
+type RawValue []byte
+
+func (pa *Parser) ParseRaw() (RawValue, error) {
+	pa.lx.skipWhiteSpaces()
+
+	mark := pa.lx.sc.startRecording()
+	err := pa.skipValue()
+	b := pa.lx.sc.stopRecording(mark)
+
+	if err != nil {
+		return nil, fmt.Errorf("parse raw error: %w", err)
 	}
 
-	var ret SampleObject1
-	seen := make(map[string]bool)
+	return RawValue(b), nil
+}
+
+// NewSubParser returns a Parser which reads raw, e.g. the object returned by
+// ParseDiscriminator, with the syntax options of pa.
+func (pa *Parser) NewSubParser(raw RawValue) Parser {
+	return NewParserWithOptions(bytes.NewReader(raw), ParserOptions{JSON5: pa.lx.json5})
+}
+
+// ParseDiscriminator parses the next object and returns the string value of
+// the key along with the raw object. The key may appear anywhere in the object.
+func (pa *Parser) ParseDiscriminator(key string) (string, RawValue, error) {
+	raw, err := pa.ParseRaw()
+	if err != nil {
+		return "", nil, fmt.Errorf("parse raw error: %w", err)
+	}
+
+	sub := pa.NewSubParser(raw)
+	v, err := sub.findDiscriminator(key)
+	if err != nil {
+		return "", nil, fmt.Errorf("find %s error: %w", key, err)
+	}
+
+	return v, raw, nil
+}
+
+func (pa *Parser) findDiscriminator(key string) (string, error) {
+	if !pa.lx.ExpectBeginObject() {
+		return "", errors.New("expect begin object")
+	}
 
+	// empty object
 	if pa.lx.NextTokenType() == TokenTypeEndObject {
-		pa.lx.sc.Skip(1)
-		goto Validate
+		return "", fmt.Errorf("missing %s", key)
 	}
 
 	for {
-		k, ok := pa.lx.ExpectString()
-		if !ok {
-			return SampleObject1{}, errors.New("expect string")
-		}
-		if seen[k] {
-			return SampleObject1{}, errors.New("duplicate key")
+		k, err := pa.parseKey()
+		if err != nil {
+			return "", fmt.Errorf("parse key error: %w", err)
 		}
-		seen[k] = true
 
 		if !pa.lx.ExpectNameSeparator() {
-			return SampleObject1{}, errors.New("expect name separator")
+			return "", errors.New("expect name separator")
 		}
 
-		switch k {
-		case "boolean":
-			v, err := pa.ParseBool()
+		if k == key {
+			return pa.ParseString()
+		}
+
+		if err := pa.skipValue(); err != nil {
+			return "", fmt.Errorf("skip value error: %w", err)
+		}
+
+		if pa.lx.NextTokenType() == TokenTypeEndObject {
+			return "", fmt.Errorf("missing %s", key)
+		}
+		pa.lx.sc.Skip(1)
+		if pa.lx.TrailingComma(TokenTypeEndObject) {
+			return "", fmt.Errorf("missing %s", key)
+		}
+	}
+}
+
+func (pa *Parser) skipValue() error {
+	var err error
+
+	switch pa.lx.NextTokenType() {
+	case TokenTypeBeginArray:
+		err = pa.skipArray()
+	case TokenTypeBeginObject:
+		err = pa.skipObject()
+	case TokenTypeNull:
+		_, err = pa.ParseNull()
+	case TokenTypeBool:
+		_, err = pa.ParseBool()
+	case TokenTypeNumber:
+		if _, ok := pa.lx.ExpectNumberBytes(); !ok {
+			err = errors.New("expect number")
+		}
+	case TokenTypeString:
+		_, err = pa.ParseString()
+	default:
+		return errors.New("invalid token type")
+	}
+
+	if err != nil {
+		return fmt.Errorf("skip error: %w", err)
+	}
+
+	return nil
+}
+
+func (pa *Parser) skipArray() error {
+	if !pa.lx.ExpectBeginArray() {
+		return errors.New("expect begin array")
+	}
+
+	// empty array
+	if pa.lx.NextTokenType() == TokenTypeEndArray {
+		pa.lx.sc.Skip(1)
+		return nil
+	}
+
+	for {
+		if err := pa.skipValue(); err != nil {
+			return fmt.Errorf("skip value error: %w", err)
+		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndArray:
+			pa.lx.sc.Skip(1)
+			return nil
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndArray) {
+				pa.lx.sc.Skip(1)
+				return nil
+			}
+
+		default:
+			return errors.New("expect value separator or end array")
+		}
+	}
+}
+
+func (pa *Parser) skipObject() error {
+	if !pa.lx.ExpectBeginObject() {
+		return errors.New("expect begin object")
+	}
+
+	// empty object
+	if pa.lx.NextTokenType() == TokenTypeEndObject {
+		pa.lx.sc.Skip(1)
+		return nil
+	}
+
+	for {
+		if _, err := pa.parseKey(); err != nil {
+			return fmt.Errorf("skip key error: %w", err)
+		}
+
+		if !pa.lx.ExpectNameSeparator() {
+			return errors.New("expect name separator")
+		}
+
+		if err := pa.skipValue(); err != nil {
+			return fmt.Errorf("skip value error: %w", err)
+		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndObject:
+			pa.lx.sc.Skip(1)
+			return nil
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndObject) {
+				pa.lx.sc.Skip(1)
+				return nil
+			}
+
+		default:
+			return errors.New("expect value separator or end object")
+		}
+	}
+}
+
+// sampleObject1Keys and sampleObject1Fields are the perfect hash table of the
+// keys of SampleObject1. The fields are numbered from 1, and 0 means an unknown
+// key.
+const sampleObject1KeySeed = 7
+
+var sampleObject1Keys = [16]string{
+	5:  "string",
+	6:  "boolean",
+	7:  "object2",
+	9:  "object",
+	10: "object2_array",
+	11: "float64",
+	14: "array",
+	15: "any",
+}
+
+var sampleObject1Fields = [16]uint8{
+	5:  3, // string
+	6:  1, // boolean
+	7:  7, // object2
+	9:  4, // object
+	10: 8, // object2_array
+	11: 2, // float64
+	14: 5, // array
+	15: 6, // any
+}
+
+func (pa *Parser) ParseSampleObject1() (SampleObject1, error) {
+	if !pa.lx.ExpectBeginObject() {
+		return SampleObject1{}, errors.New("expect begin object")
+	}
+
+	var ret SampleObject1
+	var seen uint64
+
+	if pa.lx.NextTokenType() == TokenTypeEndObject {
+		pa.lx.sc.Skip(1)
+		goto Validate
+	}
+
+	for {
+		k, ok := pa.lx.ExpectKeyBytes()
+		if !ok {
+			return SampleObject1{}, errors.New("expect string")
+		}
+
+		slot := KeyHash(k, sampleObject1KeySeed) & 15
+		f := sampleObject1Fields[slot]
+		if string(k) != sampleObject1Keys[slot] {
+			f = 0
+		}
+		if seen&(1<<f) != 0 {
+			return SampleObject1{}, errors.New("duplicate key")
+		}
+		seen |= 1 << f
+
+		if !pa.lx.ExpectNameSeparator() {
+			return SampleObject1{}, errors.New("expect name separator")
+		}
+
+		switch f {
+		case 1: // boolean
+			v, err := pa.ParseBool()
 			if err != nil {
 				return SampleObject1{}, fmt.Errorf("parse boolean error: %w", err)
 			}
 			ret.Boolean = v
 
-		case "float64":
+		case 2: // float64
 			v, err := pa.ParseFloat64()
 			if err != nil {
 				return SampleObject1{}, fmt.Errorf("parse float64 error: %w", err)
 			}
 			ret.Float64 = v
 
-		case "string":
+		case 3: // string
 			v, err := pa.ParseString()
 			if err != nil {
 				return SampleObject1{}, fmt.Errorf("parse string error: %w", err)
 			}
 			ret.String = v
 
-		case "object":
+		case 4: // object
 			v, err := pa.ParseObject()
 			if err != nil {
 				return SampleObject1{}, fmt.Errorf("parse object error: %w", err)
 			}
 			ret.Object = v
 
-		case "array":
+		case 5: // array
 			v, err := pa.ParseArray()
 			if err != nil {
 				return SampleObject1{}, fmt.Errorf("parse array error: %w", err)
 			}
 			ret.Array = v
 
-		case "any":
+		case 6: // any
 			v, err := pa.ParseValue()
 			if err != nil {
 				return SampleObject1{}, fmt.Errorf("parse any error: %w", err)
 			}
 			ret.Any = v
 
-		case "object2":
+		case 7: // object2
 			v, err := pa.ParseSampleObject2()
 			if err != nil {
-				return SampleObject1{}, fmt.Errorf("parse object2 error: %w", err)
+				return SampleObject1{}, fmt.Errorf(
+					"parse object2 error: %w",
+					PrependPath(err, ".object2"),
+				)
 			}
 			ret.Object2 = v
 
-		case "object2_array":
+		case 8: // object2_array
 			v, err := pa.ParseSampleObject2Array()
 			if err != nil {
-				return SampleObject1{}, fmt.Errorf("parse object2_array error: %w", err)
+				return SampleObject1{}, fmt.Errorf(
+					"parse object2_array error: %w",
+					PrependPath(err, ".object2_array"),
+				)
 			}
 			ret.Object2Array = v
 
 		default:
 			return SampleObject1{}, errors.New("unknown key")
 		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndObject:
+			pa.lx.sc.Skip(1)
+			goto Validate
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndObject) {
+				pa.lx.sc.Skip(1)
+				goto Validate
+			}
+
+		default:
+			return SampleObject1{}, errors.New("expect value separator or end object")
+		}
 	}
 
 Validate:
-	if !seen["boolean"] {
+	if seen&(1<<1) == 0 {
 		return SampleObject1{}, errors.New("missing boolean")
 	}
-	if !seen["float64"] {
+	if seen&(1<<2) == 0 {
 		return SampleObject1{}, errors.New("missing float64")
 	}
-	if !seen["string"] {
+	if seen&(1<<3) == 0 {
 		return SampleObject1{}, errors.New("missing string")
 	}
-	if !seen["object"] {
+	if seen&(1<<4) == 0 {
 		return SampleObject1{}, errors.New("missing object")
 	}
-	if !seen["array"] {
+	if seen&(1<<5) == 0 {
 		return SampleObject1{}, errors.New("missing array")
 	}
-	if !seen["any"] {
+	if seen&(1<<6) == 0 {
 		return SampleObject1{}, errors.New("missing any")
 	}
-	if !seen["object2"] {
+	if seen&(1<<7) == 0 {
 		return SampleObject1{}, errors.New("missing object2")
 	}
-	if !seen["object2_array"] {
+	if seen&(1<<8) == 0 {
 		return SampleObject1{}, errors.New("missing object2_array")
 	}
 
 	return ret, nil
 }
 
+// sampleObject2Keys and sampleObject2Fields are the perfect hash table of the
+// keys of SampleObject2. The fields are numbered from 1, and 0 means an unknown
+// key.
+const sampleObject2KeySeed = 26
+
+var sampleObject2Keys = [8]string{
+	0: "array",
+	1: "any",
+	2: "object",
+	3: "float64",
+	4: "string",
+	7: "boolean",
+}
+
+var sampleObject2Fields = [8]uint8{
+	0: 5, // array
+	1: 6, // any
+	2: 4, // object
+	3: 1, // float64
+	4: 2, // string
+	7: 3, // boolean
+}
+
 func (pa *Parser) ParseSampleObject2() (SampleObject2, error) {
 	if !pa.lx.ExpectBeginObject() {
 		return SampleObject2{}, errors.New("expect begin object")
 	}
 
 	var ret SampleObject2
-	seen := make(map[string]bool)
+	var seen uint64
 
 	if pa.lx.NextTokenType() == TokenTypeEndObject {
 		pa.lx.sc.Skip(1)
@@ -1043,56 +1808,62 @@ func (pa *Parser) ParseSampleObject2() (SampleObject2, error) {
 	}
 
 	for {
-		k, ok := pa.lx.ExpectString()
+		k, ok := pa.lx.ExpectKeyBytes()
 		if !ok {
 			return SampleObject2{}, errors.New("expect string")
 		}
-		if seen[k] {
+
+		slot := KeyHash(k, sampleObject2KeySeed) & 7
+		f := sampleObject2Fields[slot]
+		if string(k) != sampleObject2Keys[slot] {
+			f = 0
+		}
+		if seen&(1<<f) != 0 {
 			return SampleObject2{}, errors.New("duplicate key")
 		}
-		seen[k] = true
+		seen |= 1 << f
 
 		if !pa.lx.ExpectNameSeparator() {
 			return SampleObject2{}, errors.New("expect name separator")
 		}
 
-		switch k {
-		case "float64":
+		switch f {
+		case 1: // float64
 			v, err := pa.ParseFloat64()
 			if err != nil {
 				return SampleObject2{}, fmt.Errorf("parse float64 error: %w", err)
 			}
 			ret.Float64 = v
 
-		case "string":
+		case 2: // string
 			v, err := pa.ParseString()
 			if err != nil {
 				return SampleObject2{}, fmt.Errorf("parse string error: %w", err)
 			}
 			ret.String = v
 
-		case "boolean":
+		case 3: // boolean
 			v, err := pa.ParseBool()
 			if err != nil {
 				return SampleObject2{}, fmt.Errorf("parse boolean error: %w", err)
 			}
 			ret.Boolean = v
 
-		case "object":
+		case 4: // object
 			v, err := pa.ParseObject()
 			if err != nil {
 				return SampleObject2{}, fmt.Errorf("parse object error: %w", err)
 			}
 			ret.Object = v
 
-		case "array":
+		case 5: // array
 			v, err := pa.ParseArray()
 			if err != nil {
 				return SampleObject2{}, fmt.Errorf("parse array error: %w", err)
 			}
 			ret.Array = v
 
-		case "any":
+		case 6: // any
 			v, err := pa.ParseValue()
 			if err != nil {
 				return SampleObject2{}, fmt.Errorf("parse any error: %w", err)
@@ -1102,25 +1873,41 @@ func (pa *Parser) ParseSampleObject2() (SampleObject2, error) {
 		default:
 			return SampleObject2{}, errors.New("unknown key")
 		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndObject:
+			pa.lx.sc.Skip(1)
+			goto Validate
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndObject) {
+				pa.lx.sc.Skip(1)
+				goto Validate
+			}
+
+		default:
+			return SampleObject2{}, errors.New("expect value separator or end object")
+		}
 	}
 
 Validate:
-	if !seen["float64"] {
+	if seen&(1<<1) == 0 {
 		return SampleObject2{}, errors.New("missing float64")
 	}
-	if !seen["string"] {
+	if seen&(1<<2) == 0 {
 		return SampleObject2{}, errors.New("missing string")
 	}
-	if !seen["boolean"] {
+	if seen&(1<<3) == 0 {
 		return SampleObject2{}, errors.New("missing boolean")
 	}
-	if !seen["object"] {
+	if seen&(1<<4) == 0 {
 		return SampleObject2{}, errors.New("missing object")
 	}
-	if !seen["array"] {
+	if seen&(1<<5) == 0 {
 		return SampleObject2{}, errors.New("missing array")
 	}
-	if !seen["any"] {
+	if seen&(1<<6) == 0 {
 		return SampleObject2{}, errors.New("missing any")
 	}
 
@@ -1143,7 +1930,7 @@ func (pa *Parser) ParseSampleObject2Array() ([]SampleObject2, error) {
 	// first value
 	v, err := pa.ParseSampleObject2()
 	if err != nil {
-		return nil, fmt.Errorf("parse value error: %w", err)
+		return nil, fmt.Errorf("parse value error: %w", PrependPath(err, "[0]"))
 	}
 	ret = append(ret, v)
 
@@ -1155,6 +1942,10 @@ func (pa *Parser) ParseSampleObject2Array() ([]SampleObject2, error) {
 
 		case TokenTypeValueSeparator:
 			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndArray) {
+				pa.lx.sc.Skip(1)
+				return ret, nil
+			}
 
 		default:
 			return nil, errors.New("expect value separator or end array")
@@ -1162,8 +1953,294 @@ func (pa *Parser) ParseSampleObject2Array() ([]SampleObject2, error) {
 
 		v, err := pa.ParseSampleObject2()
 		if err != nil {
-			return nil, fmt.Errorf("parse value error: %w", err)
+			return nil, fmt.Errorf("parse value error: %w", PrependPath(err, IndexPath(len(ret))))
 		}
 		ret = append(ret, v)
 	}
 }
+
+func (pa *Parser) ParseSampleEvent() (SampleEvent, error) {
+	kind, raw, err := pa.ParseDiscriminator("kind")
+	if err != nil {
+		return nil, fmt.Errorf("parse kind error: %w", err)
+	}
+
+	sub := pa.NewSubParser(raw)
+
+	switch kind {
+	case "created":
+		v, err := sub.ParseSampleCreatedEvent()
+		if err != nil {
+			return nil, fmt.Errorf("parse created error: %w", err)
+		}
+		return v, nil
+
+	case "deleted":
+		v, err := sub.ParseSampleDeletedEvent()
+		if err != nil {
+			return nil, fmt.Errorf("parse deleted error: %w", err)
+		}
+		return v, nil
+
+	default:
+		return nil, fmt.Errorf("unknown kind %q", kind)
+	}
+}
+
+// sampleCreatedEventKeys and sampleCreatedEventFields are the perfect hash
+// table of the keys of SampleCreatedEvent. The fields are numbered from 1, and
+// 0 means an unknown key.
+const sampleCreatedEventKeySeed = 0
+
+var sampleCreatedEventKeys = [8]string{
+	1: "kind",
+	2: "name",
+	4: "id",
+	5: "event_id",
+	6: "eventId",
+}
+
+var sampleCreatedEventFields = [8]uint8{
+	1: 1, // kind
+	2: 3, // name
+	4: 2, // id
+	5: 2, // id
+	6: 2, // id
+}
+
+func (pa *Parser) ParseSampleCreatedEvent() (SampleCreatedEvent, error) {
+	if !pa.lx.ExpectBeginObject() {
+		return SampleCreatedEvent{}, errors.New("expect begin object")
+	}
+
+	var ret SampleCreatedEvent
+	var seen uint64
+
+	if pa.lx.NextTokenType() == TokenTypeEndObject {
+		pa.lx.sc.Skip(1)
+		goto Validate
+	}
+
+	for {
+		k, ok := pa.lx.ExpectKeyBytes()
+		if !ok {
+			return SampleCreatedEvent{}, errors.New("expect string")
+		}
+
+		slot := KeyHash(k, sampleCreatedEventKeySeed) & 7
+		f := sampleCreatedEventFields[slot]
+		if string(k) != sampleCreatedEventKeys[slot] {
+			f = 0
+		}
+		if f == 0 && bytes.EqualFold(k, []byte("name")) {
+			f = 3
+		}
+		if seen&(1<<f) != 0 {
+			return SampleCreatedEvent{}, errors.New("duplicate key")
+		}
+		seen |= 1 << f
+
+		if !pa.lx.ExpectNameSeparator() {
+			return SampleCreatedEvent{}, errors.New("expect name separator")
+		}
+
+		switch f {
+		case 1: // kind
+			// discriminator
+			if err := pa.skipValue(); err != nil {
+				return SampleCreatedEvent{}, fmt.Errorf("skip kind error: %w", err)
+			}
+
+		case 2: // id
+			v, err := pa.ParseString()
+			if err != nil {
+				return SampleCreatedEvent{}, fmt.Errorf("parse id error: %w", err)
+			}
+			ret.ID = v
+
+		case 3: // name
+			v, err := pa.ParseString()
+			if err != nil {
+				return SampleCreatedEvent{}, fmt.Errorf("parse name error: %w", err)
+			}
+			if n := utf8.RuneCountInString(v); n < 1 {
+				return SampleCreatedEvent{}, &ValidationError{
+					Path: ".name",
+					Rule: "minLength",
+					Msg:  fmt.Sprintf("length %d is less than 1", n),
+				}
+			} else if n > 64 {
+				return SampleCreatedEvent{}, &ValidationError{
+					Path: ".name",
+					Rule: "maxLength",
+					Msg:  fmt.Sprintf("length %d is greater than 64", n),
+				}
+			}
+			ret.Name = v
+
+		default:
+			return SampleCreatedEvent{}, errors.New("unknown key")
+		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndObject:
+			pa.lx.sc.Skip(1)
+			goto Validate
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndObject) {
+				pa.lx.sc.Skip(1)
+				goto Validate
+			}
+
+		default:
+			return SampleCreatedEvent{}, errors.New("expect value separator or end object")
+		}
+	}
+
+Validate:
+	if seen&(1<<2) == 0 {
+		return SampleCreatedEvent{}, errors.New("missing id")
+	}
+	if seen&(1<<3) == 0 {
+		return SampleCreatedEvent{}, errors.New("missing name")
+	}
+
+	return ret, nil
+}
+
+// sampleDeletedEventKeys and sampleDeletedEventFields are the perfect hash
+// table of the keys of SampleDeletedEvent. The fields are numbered from 1, and
+// 0 means an unknown key.
+const sampleDeletedEventKeySeed = 0
+
+var sampleDeletedEventKeys = [4]string{
+	0: "id",
+	1: "kind",
+	3: "reason",
+}
+
+var sampleDeletedEventFields = [4]uint8{
+	0: 2, // id
+	1: 1, // kind
+	3: 3, // reason
+}
+
+func (pa *Parser) ParseSampleDeletedEvent() (SampleDeletedEvent, error) {
+	if !pa.lx.ExpectBeginObject() {
+		return SampleDeletedEvent{}, errors.New("expect begin object")
+	}
+
+	var ret SampleDeletedEvent
+	var seen uint64
+
+	if pa.lx.NextTokenType() == TokenTypeEndObject {
+		pa.lx.sc.Skip(1)
+		goto Validate
+	}
+
+	for {
+		k, ok := pa.lx.ExpectKeyBytes()
+		if !ok {
+			return SampleDeletedEvent{}, errors.New("expect string")
+		}
+
+		slot := KeyHash(k, sampleDeletedEventKeySeed) & 3
+		f := sampleDeletedEventFields[slot]
+		if string(k) != sampleDeletedEventKeys[slot] {
+			f = 0
+		}
+		if seen&(1<<f) != 0 {
+			return SampleDeletedEvent{}, errors.New("duplicate key")
+		}
+		seen |= 1 << f
+
+		if !pa.lx.ExpectNameSeparator() {
+			return SampleDeletedEvent{}, errors.New("expect name separator")
+		}
+
+		switch f {
+		case 1: // kind
+			// discriminator
+			if err := pa.skipValue(); err != nil {
+				return SampleDeletedEvent{}, fmt.Errorf("skip kind error: %w", err)
+			}
+
+		case 2: // id
+			v, err := pa.ParseString()
+			if err != nil {
+				return SampleDeletedEvent{}, fmt.Errorf("parse id error: %w", err)
+			}
+			ret.ID = v
+
+		case 3: // reason
+			v, err := pa.ParseString()
+			if err != nil {
+				return SampleDeletedEvent{}, fmt.Errorf("parse reason error: %w", err)
+			}
+			if err := validateSampleDeletedEventReason(v); err != nil {
+				return SampleDeletedEvent{}, err
+			}
+			ret.Reason = v
+
+		default:
+			return SampleDeletedEvent{}, errors.New("unknown key")
+		}
+
+		switch pa.lx.NextTokenType() {
+		case TokenTypeEndObject:
+			pa.lx.sc.Skip(1)
+			goto Validate
+
+		case TokenTypeValueSeparator:
+			pa.lx.sc.Skip(1)
+			if pa.lx.TrailingComma(TokenTypeEndObject) {
+				pa.lx.sc.Skip(1)
+				goto Validate
+			}
+
+		default:
+			return SampleDeletedEvent{}, errors.New("expect value separator or end object")
+		}
+	}
+
+Validate:
+	if seen&(1<<2) == 0 {
+		return SampleDeletedEvent{}, errors.New("missing id")
+	}
+	if seen&(1<<3) == 0 {
+		ret.Reason = sampleDeletedEventReasonDefault
+	}
+
+	return ret, nil
+}
+
+// sampleDeletedEventReasonDefault is the default value of the reason field. It
+// is parsed and validated once, so an invalid default is found at
+// initialization.
+var sampleDeletedEventReasonDefault = func() string {
+	pa := NewParser(strings.NewReader(`"unknown"`))
+	v, err := pa.ParseString()
+	if err == nil {
+		err = validateSampleDeletedEventReason(v)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("parse default reason error: %v", err))
+	}
+
+	return v
+}()
+
+func validateSampleDeletedEventReason(v string) error {
+	switch v {
+	case "unknown", "expired", "canceled":
+		return nil
+	}
+
+	return &ValidationError{
+		Path: ".reason",
+		Rule: "enum",
+		Msg:  fmt.Sprintf("%q is not one of %q", v, []string{"unknown", "expired", "canceled"}),
+	}
+}
@@ -2,9 +2,12 @@ package mocjson
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"reflect"
 	"strconv"
@@ -2429,6 +2432,149 @@ func BenchmarkLexer_ExpectString(b *testing.B) {
 	}
 }
 
+func TestLexer_ExpectStringBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		b      []byte
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "ok: empty string",
+			b:      []byte(`""`),
+			want:   "",
+			wantOK: true,
+		},
+		{
+			name:   "ok: simple string",
+			b:      []byte(`"hello"`),
+			want:   "hello",
+			wantOK: true,
+		},
+		{
+			name:   "ok: string with escape characters",
+			b:      []byte(`"hello\nworld"`),
+			want:   "hello\nworld",
+			wantOK: true,
+		},
+		{
+			name:   "ok: multi-byte string",
+			b:      []byte(`"こんにちは"`),
+			want:   "こんにちは",
+			wantOK: true,
+		},
+		{
+			name:   "ok: long string",
+			b:      []byte(`"` + strings.Repeat("a", ScannerBufSize*2) + `"`),
+			want:   strings.Repeat("a", ScannerBufSize*2),
+			wantOK: true,
+		},
+		{
+			name:   "ng: unterminated string",
+			b:      []byte(`"hello`),
+			want:   "",
+			wantOK: false,
+		},
+		{
+			name:   "ng: control character",
+			b:      []byte("\"hello\nworld\""),
+			want:   "",
+			wantOK: false,
+		},
+		{
+			name:   "ng: not a string",
+			b:      []byte(`null`),
+			want:   "",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bytes.NewReader(tt.b)
+			lx := NewLexer(r)
+
+			got, gotOK := lx.ExpectStringBytes()
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if gotOK != tt.wantOK {
+				t.Errorf("gotOK %v, wantOK %v", gotOK, tt.wantOK)
+			}
+		})
+
+		t.Run(tt.name+" (iotest.OneByteReader)", func(t *testing.T) {
+			t.Parallel()
+
+			r := iotest.OneByteReader(bytes.NewReader(tt.b))
+			lx := NewLexer(r)
+
+			got, gotOK := lx.ExpectStringBytes()
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if gotOK != tt.wantOK {
+				t.Errorf("gotOK %v, wantOK %v", gotOK, tt.wantOK)
+			}
+		})
+	}
+}
+
+func BenchmarkLexer_ExpectStringBytes(b *testing.B) {
+	bs := []byte(`"object2_array"`)
+
+	r := bytes.NewReader(bs)
+	lx := NewLexer(r)
+
+	b.ResetTimer()
+	for b.Loop() {
+		r.Reset(bs)
+		lx.reset()
+		lx.ExpectStringBytes()
+	}
+}
+
+func TestLexer_ExpectKeyBytes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		b      string
+		json5  bool
+		want   string
+		wantOK bool
+	}{
+		{"ok: string", `"key"`, false, "key", true},
+		{"ok: escaped string", `"k\u0065y"`, false, "key", true},
+		{"ng: identifier", `key`, false, "", false},
+		{"ng: single-quoted", `'key'`, false, "", false},
+		{"ok: json5 string", `"key"`, true, "key", true},
+		{"ok: json5 identifier", `  key:`, true, "key", true},
+		{"ok: json5 single-quoted", `'key'`, true, "key", true},
+		{"ng: json5 number", `1`, true, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParserWithOptions(strings.NewReader(tt.b), ParserOptions{JSON5: tt.json5})
+
+			got, gotOK := pa.Lexer().ExpectKeyBytes()
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if gotOK != tt.wantOK {
+				t.Errorf("gotOK %v, wantOK %v", gotOK, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestParser_Parse(t *testing.T) {
 	t.Parallel()
 
@@ -2518,6 +2664,260 @@ func TestParser_Parse(t *testing.T) {
 	}
 }
 
+// cancelReader cancels the context at the n-th Read, and counts the reads.
+type cancelReader struct {
+	r      io.Reader
+	n      int
+	reads  int
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	r.reads++
+	if r.reads == r.n {
+		r.cancel()
+	}
+	return r.r.Read(p)
+}
+
+func TestParser_ParseContext(t *testing.T) {
+	t.Parallel()
+
+	long := `[` + strings.Repeat(`"0123456789",`, 100000) + `null]`
+
+	tests := []struct {
+		name string
+		b    string
+		// cancelAt is the Read at which the context is canceled.
+		cancelAt   int
+		want       any
+		wantErr    bool
+		wantCancel bool
+	}{
+		{
+			name: "not canceled",
+			b:    `{"a":[1]}`,
+			want: map[string]any{"a": []any{1.0}},
+		},
+		{
+			name:       "canceled at first read",
+			b:          long,
+			cancelAt:   1,
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "canceled during parse",
+			b:          long,
+			cancelAt:   10,
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:       "canceled after value",
+			b:          `{"a":1}` + strings.Repeat(" ", ScannerBufSize),
+			cancelAt:   1,
+			wantErr:    true,
+			wantCancel: true,
+		},
+		{
+			name:    "syntax error",
+			b:       `[1,]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			r := &cancelReader{
+				r:      iotest.OneByteReader(strings.NewReader(tt.b)),
+				n:      tt.cancelAt,
+				cancel: cancel,
+			}
+			pa := NewParser(r)
+
+			got, err := pa.ParseContext(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, context.Canceled) != tt.wantCancel {
+				t.Errorf("got %v, wantCancel %v", err, tt.wantCancel)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			// The OneByteReader is read ScannerBufSize times to fill the
+			// buffer, so the parse stops within a buffer.
+			if tt.wantCancel && r.reads > tt.cancelAt+ScannerBufSize {
+				t.Errorf("got %d reads after cancellation", r.reads-tt.cancelAt)
+			}
+		})
+	}
+}
+
+func TestParser_ParseContext_Deadline(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	pa := NewParser(strings.NewReader(`null`))
+	if _, err := pa.ParseContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestParser_ParseJSON5(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		b    string
+		want any
+		// json is set if b is also valid JSON.
+		json    bool
+		wantErr bool
+	}{
+		{
+			name: "plain JSON",
+			b:    `{"a": [1, "x", null]}`,
+			want: map[string]any{"a": []any{1.0, "x", nil}},
+			json: true,
+		},
+		{
+			name: "line comments",
+			b:    "// head\n[1, // one\n2] // tail",
+			want: []any{1.0, 2.0},
+		},
+		{
+			name: "block comments",
+			b:    `/* a */{/**/"a"/* b */:/* c */1/* d */}`,
+			want: map[string]any{"a": 1.0},
+		},
+		{
+			name: "long block comment",
+			b:    "[/*" + strings.Repeat("*", 3*ScannerBufSize) + "/1]",
+			want: []any{1.0},
+		},
+		{
+			name:    "unterminated block comment",
+			b:       `[1] /* a`,
+			wantErr: true,
+		},
+		{
+			name:    "slash",
+			b:       `[1] /`,
+			wantErr: true,
+		},
+		{
+			name: "trailing commas",
+			b:    `{"a": [1, [],], "b": {"c": 2,},}`,
+			want: map[string]any{"a": []any{1.0, []any{}}, "b": map[string]any{"c": 2.0}},
+		},
+		{
+			name:    "double trailing commas",
+			b:       `[1,,]`,
+			wantErr: true,
+		},
+		{
+			name:    "only comma",
+			b:       `{,}`,
+			wantErr: true,
+		},
+		{
+			name: "single quotes",
+			b:    `['a"b\'c', "d\'e", 'ü']`,
+			want: []any{`a"b'c`, "d'e", "ü"},
+		},
+		{
+			name: "identifier keys",
+			b:    `{a: 1, $b_2: 2, ünï: 3, null: 4, 'e': 5}`,
+			want: map[string]any{"a": 1.0, "$b_2": 2.0, "ünï": 3.0, "null": 4.0, "e": 5.0},
+		},
+		{
+			name:    "key starting with digit",
+			b:       `{1a: 1}`,
+			wantErr: true,
+		},
+		{
+			name:    "identifier value",
+			b:       `{a: b}`,
+			wantErr: true,
+		},
+		{
+			name: "numbers",
+			b:    `[0x1F, -0XfF, +1.5, Infinity, -Infinity, +Infinity]`,
+			want: []any{31.0, -255.0, 1.5, math.Inf(1), math.Inf(-1), math.Inf(1)},
+		},
+		{
+			name: "NaN",
+			b:    `[NaN, -NaN, +NaN]`,
+			want: []any{math.NaN(), math.NaN(), math.NaN()},
+		},
+		{
+			name:    "hex without digits",
+			b:       `0x`,
+			wantErr: true,
+		},
+		{
+			name:    "plus minus",
+			b:       `+-1`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParserWithOptions(strings.NewReader(tt.b), ParserOptions{JSON5: true})
+			got, err := pa.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			// NaN is not equal to itself.
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			strict := NewParser(strings.NewReader(tt.b))
+			if _, err := strict.Parse(); (err == nil) != tt.json {
+				t.Errorf("got strict error %v, want valid %v", err, tt.json)
+			}
+
+			raw := NewParserWithOptions(strings.NewReader(tt.b), ParserOptions{JSON5: true})
+			if _, err := raw.ParseRaw(); err != nil {
+				t.Errorf("parse raw error: %v", err)
+			}
+			if !raw.Lexer().ExpectEOF() {
+				t.Error("expect EOF after raw value")
+			}
+		})
+	}
+}
+
+func BenchmarkParser_ParseJSON5(b *testing.B) {
+	s := "// config\n{\n" + strings.Repeat("  key: ['a', 0x1F, +1.5, /* n */ NaN,],\n", 100) + "}"
+	r := strings.NewReader(s)
+
+	for b.Loop() {
+		r.Reset(s)
+		pa := NewParserWithOptions(r, ParserOptions{JSON5: true})
+		if _, err := pa.Parse(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkParser_Parse(b *testing.B) {
 	bs := []byte(`
 [
@@ -3466,13 +3866,14 @@ func BenchmarkParser_ParseRat(b *testing.B) {
 	}
 }
 
-func TestParser_ParseFloat64(t *testing.T) {
+func TestParser_ParseInt64(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name    string
 		b       []byte
-		want    float64
+		json5   bool
+		want    int64
 		wantErr bool
 	}{
 		{
@@ -3481,29 +3882,110 @@ func TestParser_ParseFloat64(t *testing.T) {
 			want: 0,
 		},
 		{
-			name: "ok: 1",
-			b:    []byte("1"),
-			want: 1,
+			name: "ok: -9223372036854775808",
+			b:    []byte("-9223372036854775808"),
+			want: -9223372036854775808,
 		},
 		{
-			name: "ok: 1234567890",
-			b:    []byte("1234567890"),
-			want: 1234567890,
+			name: "ok: 1.0",
+			b:    []byte("1.0"),
+			want: 1,
 		},
 		{
-			name: "ok: 0.0",
-			b:    []byte("0.0"),
-			want: 0.0,
+			name: "ok: 1e2",
+			b:    []byte("1e2"),
+			want: 100,
 		},
 		{
-			name: "ok: 1.0",
-			b:    []byte("1.0"),
-			want: 1.0,
+			name:  "ok: json5 0x10",
+			b:     []byte("0x10"),
+			json5: true,
+			want:  16,
 		},
 		{
-			name: "ok: 1234567890.0123456789",
-			b:    []byte("1234567890.0123456789"),
-			want: 1234567890.0123456789,
+			name:  "ok: json5 +1",
+			b:     []byte("+1"),
+			json5: true,
+			want:  1,
+		},
+		{
+			name:    "ng: 1.5",
+			b:       []byte("1.5"),
+			wantErr: true,
+		},
+		{
+			name:    "ng: 9223372036854775808",
+			b:       []byte("9223372036854775808"),
+			wantErr: true,
+		},
+		{
+			name:    "ng: +1",
+			b:       []byte("+1"),
+			wantErr: true,
+		},
+		{
+			name:    "ng: string",
+			b:       []byte(`"1"`),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bytes.NewReader(tt.b)
+			pa := NewParserWithOptions(r, ParserOptions{JSON5: tt.json5})
+
+			got, err := pa.ParseInt64()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_ParseFloat64(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       []byte
+		want    float64
+		wantErr bool
+	}{
+		{
+			name: "ok: 0",
+			b:    []byte("0"),
+			want: 0,
+		},
+		{
+			name: "ok: 1",
+			b:    []byte("1"),
+			want: 1,
+		},
+		{
+			name: "ok: 1234567890",
+			b:    []byte("1234567890"),
+			want: 1234567890,
+		},
+		{
+			name: "ok: 0.0",
+			b:    []byte("0.0"),
+			want: 0.0,
+		},
+		{
+			name: "ok: 1.0",
+			b:    []byte("1.0"),
+			want: 1.0,
+		},
+		{
+			name: "ok: 1234567890.0123456789",
+			b:    []byte("1234567890.0123456789"),
+			want: 1234567890.0123456789,
 		},
 		{
 			name: "ok: 0.0e0",
@@ -3876,3 +4358,499 @@ func BenchmarkParser_ParseNull(b *testing.B) {
 		pa.ParseNull()
 	}
 }
+
+func TestParser_ParseRaw(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       []byte
+		want    RawValue
+		wantErr bool
+	}{
+		{
+			name: "null",
+			b:    []byte("null"),
+			want: RawValue("null"),
+		},
+		{
+			name: "number",
+			b:    []byte("-123.456e+7"),
+			want: RawValue("-123.456e+7"),
+		},
+		{
+			name: "string",
+			b:    []byte(`"hello\nA"`),
+			want: RawValue(`"hello\nA"`),
+		},
+		{
+			name: "surrounding whitespaces",
+			b:    []byte(" \t\r\n[ 1 , 2 ] \t\r\n"),
+			want: RawValue("[ 1 , 2 ]"),
+		},
+		{
+			name: "composite",
+			b: []byte(
+				"{\"null\":null,\"bool\":true,\"array\":[\"value1\",2],\"object\":{\"key1\":\"🍣😋🍺\"}}",
+			),
+			want: RawValue(
+				"{\"null\":null,\"bool\":true,\"array\":[\"value1\",2],\"object\":{\"key1\":\"🍣😋🍺\"}}",
+			),
+		},
+		{
+			name: "followed by values",
+			b:    []byte(`{"key":"value"},"next"`),
+			want: RawValue(`{"key":"value"}`),
+		},
+		{
+			name: "long array",
+			b:    []byte(`[` + strings.Repeat(`"value",`, 999) + `"value"]`),
+			want: RawValue(`[` + strings.Repeat(`"value",`, 999) + `"value"]`),
+		},
+		{
+			name:    "invalid array",
+			b:       []byte(`[1,]`),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "invalid object",
+			b:       []byte(`{"key"}`),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			b:       []byte(""),
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := iotest.OneByteReader(bytes.NewReader(tt.b))
+			pa := NewParser(r)
+
+			got, err := pa.ParseRaw()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gotErr %v, wantErr %v", err != nil, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkParser_ParseRaw(b *testing.B) {
+	bs := []byte(`
+[
+    {
+        "null": null,
+        "bool": true,
+        "number": 123.456,
+        "string": "🍣😋🍺",
+        "array": ["value1", 2],
+        "object": {
+            "key1": "value1",
+            "key2": 2
+        }
+    },
+    null
+]
+`)
+
+	r := bytes.NewReader(bs)
+	pa := NewParser(r)
+
+	b.ResetTimer()
+	for b.Loop() {
+		r.Reset(bs)
+		pa.reset()
+		pa.ParseRaw()
+	}
+}
+
+func TestParser_ParseDiscriminator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       []byte
+		key     string
+		want    string
+		wantRaw RawValue
+		wantErr bool
+	}{
+		{
+			name:    "first key",
+			b:       []byte(`{"kind":"created","id":"1"}`),
+			key:     "kind",
+			want:    "created",
+			wantRaw: RawValue(`{"kind":"created","id":"1"}`),
+		},
+		{
+			name:    "last key",
+			b:       []byte(` {"id":"1", "object":{"kind":"nested"}, "kind":"created"} `),
+			key:     "kind",
+			want:    "created",
+			wantRaw: RawValue(`{"id":"1", "object":{"kind":"nested"}, "kind":"created"}`),
+		},
+		{
+			name:    "missing key",
+			b:       []byte(`{"id":"1","object":{"kind":"nested"}}`),
+			key:     "kind",
+			wantErr: true,
+		},
+		{
+			name:    "empty object",
+			b:       []byte(`{}`),
+			key:     "kind",
+			wantErr: true,
+		},
+		{
+			name:    "not a string",
+			b:       []byte(`{"kind":1}`),
+			key:     "kind",
+			wantErr: true,
+		},
+		{
+			name:    "invalid object after key",
+			b:       []byte(`{"kind":"created",}`),
+			key:     "kind",
+			wantErr: true,
+		},
+		{
+			name:    "not an object",
+			b:       []byte(`["kind"]`),
+			key:     "kind",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bytes.NewReader(tt.b)
+			pa := NewParser(r)
+
+			got, gotRaw, err := pa.ParseDiscriminator(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gotErr %v, wantErr %v", err != nil, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if !bytes.Equal(gotRaw, tt.wantRaw) {
+				t.Errorf("got %q, want %q", gotRaw, tt.wantRaw)
+			}
+		})
+	}
+}
+
+func BenchmarkParser_ParseDiscriminator(b *testing.B) {
+	bs := []byte(`{"id": "1", "name": "🍣😋🍺", "array": [1, 2, 3], "kind": "created"}`)
+	r := bytes.NewReader(bs)
+	pa := NewParser(r)
+
+	b.ResetTimer()
+	for b.Loop() {
+		r.Reset(bs)
+		pa.reset()
+		pa.ParseDiscriminator("kind")
+	}
+}
+
+func TestParser_ParseSampleObject1(t *testing.T) {
+	t.Parallel()
+
+	object2 := `{"boolean":true,"float64":1.5,"string":"s","object":{},"array":[],"any":null}`
+	valid := `{"boolean":true,"float64":1.5,"string":"s","object":{"a":1},"array":[1],` +
+		`"any":"x","object2":` + object2 + `,"object2_array":[` + object2 + `,` + object2 + `]}`
+
+	want := SampleObject1{
+		SampleBase: SampleBase{
+			Boolean: true,
+			Float64: 1.5,
+			String:  "s",
+			Object:  map[string]any{"a": 1.0},
+			Array:   []any{1.0},
+		},
+		Any: "x",
+		Object2: SampleObject2{
+			SampleBase: SampleBase{
+				Boolean: true,
+				Float64: 1.5,
+				String:  "s",
+				Object:  map[string]any{},
+				Array:   []any{},
+			},
+		},
+	}
+	want.Object2Array = []SampleObject2{want.Object2, want.Object2}
+
+	tests := []struct {
+		name    string
+		b       string
+		want    SampleObject1
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			b:    valid,
+			want: want,
+		},
+		{
+			name: "white spaces",
+			b:    strings.NewReplacer(",", " , ", ":", " : ", "}", " } ").Replace(valid),
+			want: want,
+		},
+		{
+			name:    "missing field",
+			b:       strings.Replace(valid, `"boolean":true,`, "", 1),
+			wantErr: true,
+		},
+		{
+			name:    "missing field in object2",
+			b:       strings.Replace(valid, `"object2":{"boolean":true,`, `"object2":{`, 1),
+			wantErr: true,
+		},
+		{
+			name:    "duplicate key",
+			b:       strings.Replace(valid, `"boolean":true,`, `"boolean":true,"boolean":true,`, 1),
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			b:       strings.Replace(valid, `"boolean":true,`, `"boolean":true,"unknown":1,`, 1),
+			wantErr: true,
+		},
+		{
+			name:    "missing value separator",
+			b:       strings.Replace(valid, `"boolean":true,`, `"boolean":true `, 1),
+			wantErr: true,
+		},
+		{
+			name:    "trailing comma",
+			b:       strings.TrimSuffix(valid, "}") + ",}",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated",
+			b:       strings.TrimSuffix(valid, "}"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParser(strings.NewReader(tt.b))
+
+			got, err := pa.ParseSampleObject1()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			// The generated parser agrees with Decode.
+			pa = NewParser(strings.NewReader(tt.b))
+			var decoded SampleObject1
+			if err := pa.Decode(&decoded); (err != nil) != tt.wantErr {
+				t.Errorf("Decode gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(decoded, tt.want) {
+				t.Errorf("Decode got %v, want %v", decoded, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_ParseSample_JSON5(t *testing.T) {
+	t.Parallel()
+
+	object2 := `{boolean: true, float64: 0.5, string: 's', object: {}, array: [1,], any: null,}`
+	b := `{
+		// comment
+		boolean: true, float64: +1.5, string: 's', object: {a: 1,}, array: [1,], any: 'x',
+		object2: ` + object2 + `,
+		object2_array: [` + object2 + `, ` + object2 + `,],
+	}`
+
+	pa := NewParserWithOptions(strings.NewReader(b), ParserOptions{JSON5: true})
+	got, err := pa.ParseSampleObject1()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pa = NewParserWithOptions(strings.NewReader(b), ParserOptions{JSON5: true})
+	var want SampleObject1
+	if err := pa.Decode(&want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	pa = NewParserWithOptions(
+		strings.NewReader(`{id: '1', kind: 'deleted', reason: 'expired',}`),
+		ParserOptions{JSON5: true},
+	)
+	ev, err := pa.ParseSampleEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SampleDeletedEvent{ID: "1", Reason: "expired"}); ev != want {
+		t.Errorf("got %v, want %v", ev, want)
+	}
+
+	// Without the option, they are errors.
+	pa = NewParser(strings.NewReader(b))
+	if _, err := pa.ParseSampleObject1(); err == nil {
+		t.Errorf("got nil, want error")
+	}
+}
+
+func BenchmarkParser_ParseSampleObject1(b *testing.B) {
+	object2 := `{"boolean":true,"float64":1.5,"string":"s","object":{},"array":[],"any":null}`
+	bs := []byte(`{"boolean":true,"float64":1.5,"string":"s","object":{"a":1},"array":[1],` +
+		`"any":"x","object2":` + object2 + `,"object2_array":[` + object2 + `,` + object2 + `]}`)
+
+	r := bytes.NewReader(bs)
+	pa := NewParser(r)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		r.Reset(bs)
+		pa.reset()
+		if _, err := pa.ParseSampleObject1(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParser_ParseSampleObject2_Allocs(t *testing.T) {
+	// The keys are dispatched without materialising them, so the object
+	// allocates as much as its values do.
+	bs := []byte(`{"boolean":true,"float64":1.5,"string":"s","object":{},"array":[],"any":null}`)
+	values := []byte(`true 1.5 "s" {} [] null`)
+
+	r := bytes.NewReader(bs)
+	pa := NewParser(r)
+	got := testing.AllocsPerRun(100, func() {
+		r.Reset(bs)
+		pa.reset()
+		if _, err := pa.ParseSampleObject2(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	r = bytes.NewReader(values)
+	vpa := NewParser(r)
+	want := testing.AllocsPerRun(100, func() {
+		r.Reset(values)
+		vpa.reset()
+		_, _ = vpa.ParseBool()
+		_, _ = vpa.ParseFloat64()
+		_, _ = vpa.ParseString()
+		_, _ = vpa.ParseObject()
+		_, _ = vpa.ParseArray()
+		_, _ = vpa.ParseValue()
+	})
+
+	if got != want {
+		t.Errorf("got %v allocs, want %v", got, want)
+	}
+}
+
+func TestParser_ParseSampleEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       []byte
+		want    SampleEvent
+		wantErr bool
+	}{
+		{
+			name: "created",
+			b:    []byte(`{"kind":"created","id":"1","name":"hello"}`),
+			want: SampleCreatedEvent{ID: "1", Name: "hello"},
+		},
+		{
+			name: "created with alias and nocase",
+			b:    []byte(`{"kind":"created","eventId":"1","NAME":"hello"}`),
+			want: SampleCreatedEvent{ID: "1", Name: "hello"},
+		},
+		{
+			name:    "created with duplicate alias",
+			b:       []byte(`{"kind":"created","id":"1","event_id":"2","name":"hello"}`),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "created with empty name",
+			b:       []byte(`{"kind":"created","id":"1","name":""}`),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "deleted with unknown reason",
+			b:       []byte(`{"kind":"deleted","id":"1","reason":"lost"}`),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "deleted with discriminator at last",
+			b:    []byte(`{"id":"1","kind":"deleted"}`),
+			want: SampleDeletedEvent{ID: "1", Reason: "unknown"},
+		},
+		{
+			name: "deleted with reason",
+			b:    []byte(`{"kind":"deleted","reason":"expired","id":"1"}`),
+			want: SampleDeletedEvent{ID: "1", Reason: "expired"},
+		},
+		{
+			name:    "unknown kind",
+			b:       []byte(`{"kind":"updated","id":"1"}`),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "missing field",
+			b:       []byte(`{"kind":"created","id":"1"}`),
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			b:       []byte(`{"kind":"deleted","id":"1","name":"hello"}`),
+			want:    nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bytes.NewReader(tt.b)
+			pa := NewParser(r)
+
+			got, err := pa.ParseSampleEvent()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gotErr %v, wantErr %v", err != nil, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
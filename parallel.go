@@ -0,0 +1,292 @@
+package mocjson
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"runtime"
+	"sync"
+)
+
+const defaultParallelChunkSize = 1 << 20
+
+// ParallelOptions configures ParallelLines.
+type ParallelOptions struct {
+	// Workers is the number of the decoding goroutines. It defaults to
+	// runtime.GOMAXPROCS(0).
+	Workers int
+
+	// ChunkSize is the size in bytes of the chunks of lines handed to the
+	// workers. It defaults to 1 MiB. A chunk is longer if a line is. At most
+	// 2*Workers chunks are read ahead of the yielded lines.
+	ChunkSize int
+
+	// Unordered makes the lines yielded as soon as their chunks are decoded,
+	// instead of in the input order.
+	Unordered bool
+}
+
+// Line is a line decoded by ParallelLines.
+type Line[T any] struct {
+	// Number is the line number counted from 1.
+	Number int64
+	Value  T
+}
+
+// ParallelLines decodes the JSON Lines in r with decode on a pool of Parsers
+// across goroutines. If decode is nil, the lines are decoded with Decode. Each
+// line must be a single value; blank lines are skipped.
+//
+// A line which fails to be decoded is yielded with a *RecordError, and the
+// iteration continues. The error of r or of ctx is yielded last.
+//
+// Breaking the iteration or canceling ctx stops the decoding goroutines, but
+// the goroutine reading r stays blocked in a Read of r until it returns. The
+// caller must close r, e.g. an *os.File or a net.Conn, to release it.
+func ParallelLines[T any](
+	ctx context.Context,
+	r io.Reader,
+	decode func(*Parser) (T, error),
+	opts ParallelOptions,
+) iter.Seq2[Line[T], error] {
+	if decode == nil {
+		decode = func(pa *Parser) (T, error) {
+			var v T
+			err := pa.Decode(&v)
+			return v, err
+		}
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultParallelChunkSize
+	}
+
+	return func(yield func(Line[T], error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		chunks := make(chan lineChunk, workers)
+		results := make(chan lineResults[T], workers)
+		readErr := make(chan error, 1)
+
+		// inflight bounds the chunks which are read but not yielded, so that
+		// the results after a slow chunk do not pile up in pending.
+		inflight := make(chan struct{}, 2*workers)
+
+		go func() {
+			defer close(chunks)
+			readErr <- readLineChunks(ctx, r, chunkSize, chunks, inflight)
+		}()
+
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				w := newLineWorker(decode)
+				for c := range chunks {
+					select {
+					case results <- w.decodeChunk(c):
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		emit := func(res lineResults[T]) bool {
+			<-inflight
+
+			for _, l := range res.lines {
+				if !yield(l.line, l.err) {
+					return false
+				}
+			}
+			return true
+		}
+
+		// pending holds the results which are decoded before the earlier ones.
+		pending := make(map[int]lineResults[T])
+		next := 0
+
+		for res := range results {
+			if opts.Unordered {
+				if !emit(res) {
+					return
+				}
+				continue
+			}
+
+			pending[res.seq] = res
+			for {
+				res, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+
+				if !emit(res) {
+					return
+				}
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			yield(Line[T]{}, err)
+			return
+		}
+		if err := <-readErr; err != nil {
+			yield(Line[T]{}, err)
+		}
+	}
+}
+
+type lineChunk struct {
+	seq int
+	// line and off are the line number and the byte offset of data.
+	line int64
+	off  int64
+	data []byte
+}
+
+type lineResult[T any] struct {
+	line Line[T]
+	err  error
+}
+
+type lineResults[T any] struct {
+	seq   int
+	lines []lineResult[T]
+}
+
+// readLineChunks reads r into the chunks of whole lines of about size bytes.
+// It sends inflight a token before sending each chunk.
+func readLineChunks(
+	ctx context.Context,
+	r io.Reader,
+	size int,
+	chunks chan<- lineChunk,
+	inflight chan<- struct{},
+) error {
+	var rest []byte
+	c := lineChunk{line: 1}
+
+	for {
+		buf := make([]byte, max(size, 2*len(rest)))
+		n := copy(buf, rest)
+
+		m, err := io.ReadFull(r, buf[n:])
+		n += m
+		// The lines read before an error are decoded too.
+		eof := err != nil
+
+		i := bytes.LastIndexByte(buf[:n], '\n')
+		switch {
+		case eof:
+			i = n - 1
+		case i < 0:
+			// The line is longer than the buffer.
+			rest = buf[:n]
+			continue
+		}
+
+		c.data = buf[:i+1]
+		rest = buf[i+1 : n]
+
+		if len(c.data) > 0 {
+			select {
+			case inflight <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			select {
+			case chunks <- c:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if eof {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+
+		c.seq++
+		c.line += int64(bytes.Count(c.data, []byte{'\n'}))
+		c.off += int64(len(c.data))
+	}
+}
+
+type lineWorker[T any] struct {
+	r      bytes.Reader
+	pa     Parser
+	decode func(*Parser) (T, error)
+}
+
+func newLineWorker[T any](decode func(*Parser) (T, error)) *lineWorker[T] {
+	w := &lineWorker[T]{decode: decode}
+	w.pa = NewParser(&w.r)
+
+	return w
+}
+
+func (w *lineWorker[T]) decodeChunk(c lineChunk) lineResults[T] {
+	res := lineResults[T]{seq: c.seq}
+	num, off := c.line, c.off
+
+	for data := c.data; len(data) > 0; num++ {
+		line := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i], data[i+1:]
+		} else {
+			data = nil
+		}
+
+		if len(bytes.Trim(line, " \t\r")) > 0 {
+			v, err := w.decodeLine(line, off)
+			if err != nil {
+				err = &RecordError{Offset: off, Err: err}
+			}
+			res.lines = append(res.lines, lineResult[T]{Line[T]{num, v}, err})
+		}
+
+		off += int64(len(line)) + 1
+	}
+
+	return res
+}
+
+// decodeLine decodes the line at the offset off of the input, so that only a
+// BOM at the beginning of the input is skipped.
+func (w *lineWorker[T]) decodeLine(line []byte, off int64) (T, error) {
+	w.r.Reset(line)
+	w.pa.lx.sc.resetAt(&w.r, off)
+
+	v, err := w.decode(&w.pa)
+	if err != nil {
+		return v, err
+	}
+
+	if !w.pa.lx.ExpectEOF() {
+		return v, errors.New("expect EOF")
+	}
+
+	return v, nil
+}
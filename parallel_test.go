@@ -0,0 +1,379 @@
+package mocjson
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+type parallelTestItem struct {
+	ID int `json:"id"`
+}
+
+// parallelTestInput returns n lines, where every 7th line is invalid and every
+// 5th line is blank. It returns the results in the form "<number>:<id>" or
+// "<number>:!<offset>".
+func parallelTestInput(n int) (string, []string) {
+	var b strings.Builder
+	var want []string
+
+	for i := 1; i <= n; i++ {
+		off := b.Len()
+		switch {
+		case i%7 == 0:
+			b.WriteString(`{"id":"x"}`)
+			want = append(want, fmt.Sprintf("%d:!%d", i, off))
+		case i%5 == 0:
+			b.WriteString(" \r")
+		default:
+			fmt.Fprintf(&b, `{"id":%d}`, i)
+			want = append(want, fmt.Sprintf("%d:%d", i, i))
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.String(), want
+}
+
+func collectParallelLines(
+	t *testing.T,
+	seq func(func(Line[parallelTestItem], error) bool),
+) []string {
+	t.Helper()
+
+	var got []string
+	for l, err := range seq {
+		var re *RecordError
+		switch {
+		case errors.As(err, &re):
+			got = append(got, fmt.Sprintf("%d:!%d", l.Number, re.Offset))
+		case err != nil:
+			t.Fatal(err)
+		default:
+			got = append(got, fmt.Sprintf("%d:%d", l.Number, l.Value.ID))
+		}
+	}
+
+	return got
+}
+
+func TestParallelLines(t *testing.T) {
+	t.Parallel()
+
+	input, want := parallelTestInput(1000)
+
+	for _, opts := range []ParallelOptions{
+		{},
+		{Workers: 1, ChunkSize: 1},
+		{Workers: 4, ChunkSize: 64},
+		{Workers: 4, ChunkSize: 64, Unordered: true},
+	} {
+		t.Run(fmt.Sprintf("%+v", opts), func(t *testing.T) {
+			t.Parallel()
+
+			seq := ParallelLines[parallelTestItem](
+				context.Background(),
+				strings.NewReader(input),
+				nil,
+				opts,
+			)
+			got := collectParallelLines(t, seq)
+
+			if opts.Unordered {
+				slices.SortFunc(got, func(a, b string) int {
+					var na, nb int
+					fmt.Sscanf(a, "%d:", &na)
+					fmt.Sscanf(b, "%d:", &nb)
+					return na - nb
+				})
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParallelLines_Lines(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"no trailing newline", "{\"id\":1}\n{\"id\":2}", []string{"1:1", "2:2"}},
+		{"long line", `{"id":1` + strings.Repeat(" ", 1000) + "}\n", []string{"1:1"}},
+		{"two values", "{\"id\":1} {\"id\":2}\n{\"id\":3}\n", []string{"1:!0", "2:3"}},
+		{"syntax error", "{\"id\":\n{\"id\":2}\n", []string{"1:!0", "2:2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := ParallelOptions{Workers: 2, ChunkSize: 16}
+			seq := ParallelLines[parallelTestItem](
+				context.Background(),
+				strings.NewReader(tt.input),
+				nil,
+				opts,
+			)
+
+			if got := collectParallelLines(t, seq); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParallelLines_Decode(t *testing.T) {
+	t.Parallel()
+
+	input := `{"kind":"created","id":"1","name":"a"}` + "\n" + `{"kind":"deleted","id":"2"}` + "\n"
+
+	var got []SampleEvent
+	for l, err := range ParallelLines(
+		context.Background(),
+		strings.NewReader(input),
+		(*Parser).ParseSampleEvent,
+		ParallelOptions{},
+	) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, l.Value)
+	}
+
+	want := []SampleEvent{
+		SampleCreatedEvent{ID: "1", Name: "a"},
+		SampleDeletedEvent{ID: "2", Reason: "unknown"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParallelLines_Break(t *testing.T) {
+	t.Parallel()
+
+	input, _ := parallelTestInput(10000)
+	opts := ParallelOptions{Workers: 4, ChunkSize: 64}
+
+	n := 0
+	for range ParallelLines[parallelTestItem](context.Background(), strings.NewReader(input), nil, opts) {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+
+	if n != 3 {
+		t.Errorf("got %d lines, want 3", n)
+	}
+}
+
+func TestParallelLines_Bounded(t *testing.T) {
+	t.Parallel()
+
+	const n = 1000
+
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "{\"id\":%d}\n", i)
+	}
+
+	var decoded atomic.Int64
+	release := make(chan struct{})
+
+	decode := func(pa *Parser) (parallelTestItem, error) {
+		var v parallelTestItem
+		err := pa.Decode(&v)
+		if v.ID == 1 {
+			// The first line is slow.
+			<-release
+		}
+		decoded.Add(1)
+		return v, err
+	}
+
+	const workers = 2
+	seq := ParallelLines(
+		context.Background(),
+		strings.NewReader(b.String()),
+		decode,
+		ParallelOptions{Workers: workers, ChunkSize: 32},
+	)
+
+	done := make(chan int)
+	go func() {
+		lines := 0
+		for _, err := range seq {
+			if err == nil {
+				lines++
+			}
+		}
+		done <- lines
+	}()
+
+	// While the first chunk is decoded, only a few chunks of 32 bytes, which
+	// hold at most 3 lines each, are decoded ahead.
+	time.Sleep(100 * time.Millisecond)
+	if got, limit := decoded.Load(), int64(2*workers*3); got > limit {
+		t.Errorf("got %d lines decoded ahead, want at most %d", got, limit)
+	}
+
+	close(release)
+	if got := <-done; got != n {
+		t.Errorf("got %d lines, want %d", got, n)
+	}
+}
+
+func TestParallelLines_Error(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	errRead := errors.New("read error")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		r    io.Reader
+		want error
+		// minLines and maxLines bound the number of the decoded lines.
+		minLines, maxLines int
+	}{
+		{
+			name:     "canceled",
+			ctx:      ctx,
+			r:        strings.NewReader("{\"id\":1}\n"),
+			want:     context.Canceled,
+			minLines: 0,
+			maxLines: 1,
+		},
+		{
+			name:     "read error",
+			ctx:      context.Background(),
+			r:        io.MultiReader(strings.NewReader("{\"id\":1}\n"), iotest.ErrReader(errRead)),
+			want:     errRead,
+			minLines: 1,
+			maxLines: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got error
+			n := 0
+			for _, err := range ParallelLines[parallelTestItem](tt.ctx, tt.r, nil, ParallelOptions{}) {
+				if err != nil {
+					got = err
+					continue
+				}
+				n++
+			}
+
+			if !errors.Is(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if n < tt.minLines || n > tt.maxLines {
+				t.Errorf("got %d lines, want %d to %d", n, tt.minLines, tt.maxLines)
+			}
+		})
+	}
+}
+
+func TestParallelLines_BOM(t *testing.T) {
+	t.Parallel()
+
+	// Only the BOM at the beginning of the input is skipped.
+	r := strings.NewReader("\ufeff{\"id\":1}\n\ufeff{\"id\":2}\n{\"id\":3}\n")
+
+	var ids []int
+	var errLines []int64
+	for l, err := range ParallelLines[parallelTestItem](context.Background(), r, nil, ParallelOptions{}) {
+		if err != nil {
+			errLines = append(errLines, l.Number)
+			continue
+		}
+		ids = append(ids, l.Value.ID)
+	}
+
+	if want := []int{1, 3}; !slices.Equal(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	if want := []int64{2}; !slices.Equal(errLines, want) {
+		t.Errorf("got errors at lines %v, want %v", errLines, want)
+	}
+}
+
+func TestParallelLines_BlockingReader(t *testing.T) {
+	// Not parallel, because it counts the goroutines.
+	before := runtime.NumGoroutine()
+
+	pr, pw := io.Pipe()
+	go func() {
+		// The reader blocks after this line, because the pipe is not closed.
+		_, _ = pw.Write([]byte("1\n"))
+	}()
+
+	for l, err := range ParallelLines[int](context.Background(), pr, nil, ParallelOptions{ChunkSize: 2}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if l.Value != 1 {
+			t.Errorf("got %v, want 1", l.Value)
+		}
+		break
+	}
+
+	if err := pr.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d goroutines, want %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func BenchmarkParallelLines(b *testing.B) {
+	input := strings.Repeat(
+		`{"kind":"created","id":"1","name":"abcdefghijklmnopqrstuvwxyz"}`+"\n",
+		100000,
+	)
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			r := strings.NewReader(input)
+			opts := ParallelOptions{Workers: workers, ChunkSize: 64 << 10}
+
+			for b.Loop() {
+				r.Reset(input)
+				for _, err := range ParallelLines(context.Background(), r, (*Parser).ParseSampleEvent, opts) {
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,21 +1,46 @@
 package mocjson
 
+// SampleBase holds the fields shared by SampleObject1 and SampleObject2. Its
+// fields are promoted into their key sets.
+type SampleBase struct {
+	Boolean bool           `json:"boolean"`
+	Float64 float64        `json:"float64"`
+	String  string         `json:"string"`
+	Object  map[string]any `json:"object"`
+	Array   []any          `json:"array"`
+}
+
 type SampleObject1 struct {
-	Boolean      bool            `json:"boolean"`
-	Float64      float64         `json:"float64"`
-	String       string          `json:"string"`
-	Object       map[string]any  `json:"object"`
-	Array        []any           `json:"array"`
+	SampleBase
 	Any          any             `json:"any"`
 	Object2      SampleObject2   `json:"object2"`
 	Object2Array []SampleObject2 `json:"object2_array"`
 }
 
 type SampleObject2 struct {
-	Float64 float64        `json:"float64"`
-	String  string         `json:"string"`
-	Boolean bool           `json:"boolean"`
-	Object  map[string]any `json:"object"`
-	Array   []any          `json:"array"`
-	Any     any            `json:"any"`
+	SampleBase
+	Any any `json:"any"`
+}
+
+type SampleEvent interface {
+	sampleEvent()
+}
+
+type SampleCreatedEvent struct {
+	ID   string `json:"id"   mocjson:"alias=event_id|eventId"`
+	Name string `json:"name" mocjson:"nocase,minLength=1,maxLength=64"`
+}
+
+func (SampleCreatedEvent) sampleEvent() {}
+
+type SampleDeletedEvent struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason" mocjson:"enum=unknown|expired|canceled,default=\"unknown\""`
+}
+
+func (SampleDeletedEvent) sampleEvent() {}
+
+func init() {
+	RegisterVariant[SampleEvent, SampleCreatedEvent]("kind", "created")
+	RegisterVariant[SampleEvent, SampleDeletedEvent]("kind", "deleted")
 }
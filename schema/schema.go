@@ -0,0 +1,507 @@
+// Package schema validates JSON values against JSON Schema (draft 2020-12).
+//
+// The supported keywords are the core applicators ($ref, $defs, allOf, anyOf,
+// oneOf, not, if, then, else, properties, patternProperties,
+// additionalProperties, propertyNames, prefixItems, items, contains) and the
+// validation keywords (type, enum, const, multipleOf, maximum,
+// exclusiveMaximum, minimum, exclusiveMinimum, maxLength, minLength, pattern,
+// maxItems, minItems, uniqueItems, maxContains, minContains, maxProperties,
+// minProperties, required, dependentRequired). $ref must be a JSON Pointer into
+// the same document, such as "#/$defs/name". The other keywords, such as
+// format, are annotations and are ignored.
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/high-moctane/mocjson-go"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	// boolean is set for the boolean schemas true and false.
+	boolean *bool
+
+	types    []string
+	enum     []any
+	constant any
+	hasConst bool
+
+	multipleOf       *float64
+	maximum          *float64
+	exclusiveMaximum *float64
+	minimum          *float64
+	exclusiveMinimum *float64
+
+	maxLength *int
+	minLength *int
+	pattern   *regexp.Regexp
+
+	prefixItems []*Schema
+	items       *Schema
+	contains    *Schema
+	maxItems    *int
+	minItems    *int
+	uniqueItems bool
+	maxContains *int
+	minContains *int
+
+	properties           map[string]*Schema
+	patternProperties    []patternSchema
+	additionalProperties *Schema
+	propertyNames        *Schema
+	maxProperties        *int
+	minProperties        *int
+	required             []string
+	dependentRequired    map[string][]string
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+	ifs   *Schema
+	then  *Schema
+	elses *Schema
+
+	ref      string
+	resolved *Schema
+}
+
+type patternSchema struct {
+	re     *regexp.Regexp
+	schema *Schema
+}
+
+// Compile reads a schema document from r and compiles it.
+func Compile(r io.Reader) (*Schema, error) {
+	pa := mocjson.NewParser(r)
+
+	v, err := pa.ParseValue()
+	if err != nil {
+		return nil, fmt.Errorf("parse schema error: %w", err)
+	}
+	if !pa.Lexer().ExpectEOF() {
+		return nil, errors.New("parse schema error: expect EOF")
+	}
+
+	return CompileValue(v)
+}
+
+// CompileValue compiles a schema document which is the output of
+// mocjson.Parser.ParseValue.
+func CompileValue(v any) (*Schema, error) {
+	c := compiler{byPointer: make(map[string]*Schema)}
+
+	s, err := c.compile(v, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range c.refs {
+		target, ok := c.byPointer[strings.TrimPrefix(r.ref, "#")]
+		if !ok {
+			return nil, fmt.Errorf("%s: unresolvable $ref %q", r.ptr, r.ref)
+		}
+		r.resolved = target
+	}
+
+	if err := c.checkCycles(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+type compiler struct {
+	// byPointer maps the JSON Pointers of the subschemas to the schemas.
+	byPointer map[string]*Schema
+	refs      []refSchema
+}
+
+type refSchema struct {
+	*Schema
+	ptr string
+}
+
+func (c *compiler) compile(v any, ptr string) (*Schema, error) {
+	s := new(Schema)
+	c.byPointer[ptr] = s
+
+	if b, ok := v.(bool); ok {
+		s.boolean = &b
+		return s, nil
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: schema must be an object or a boolean", pointerOrRoot(ptr))
+	}
+
+	for k, kv := range m {
+		if err := c.compileKeyword(s, k, kv, ptr); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (c *compiler) compileKeyword(s *Schema, k string, v any, ptr string) error {
+	kptr := ptr + "/" + escapePointer(k)
+
+	var err error
+
+	switch k {
+	case "$ref":
+		ref, ok := v.(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return fmt.Errorf("%s: unsupported $ref %v", kptr, v)
+		}
+		s.ref = ref
+		c.refs = append(c.refs, refSchema{s, kptr})
+
+	case "$defs":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an object", kptr)
+		}
+		for name, d := range m {
+			if _, err := c.compile(d, kptr+"/"+escapePointer(name)); err != nil {
+				return err
+			}
+		}
+
+	case "type":
+		switch t := v.(type) {
+		case string:
+			s.types = []string{t}
+		case []any:
+			for _, e := range t {
+				name, ok := e.(string)
+				if !ok {
+					return fmt.Errorf("%s: must be a string or an array of strings", kptr)
+				}
+				s.types = append(s.types, name)
+			}
+		default:
+			return fmt.Errorf("%s: must be a string or an array of strings", kptr)
+		}
+		for _, t := range s.types {
+			if !slices.Contains(
+				[]string{"null", "boolean", "object", "array", "number", "string", "integer"},
+				t,
+			) {
+				return fmt.Errorf("%s: unknown type %q", kptr, t)
+			}
+		}
+
+	case "enum":
+		a, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an array", kptr)
+		}
+		s.enum = a
+
+	case "const":
+		s.constant, s.hasConst = v, true
+
+	case "multipleOf":
+		if s.multipleOf, err = compileNumber(v, kptr); err != nil {
+			return err
+		}
+		if *s.multipleOf <= 0 {
+			return fmt.Errorf("%s: must be greater than 0", kptr)
+		}
+
+	case "maximum":
+		s.maximum, err = compileNumber(v, kptr)
+
+	case "exclusiveMaximum":
+		s.exclusiveMaximum, err = compileNumber(v, kptr)
+
+	case "minimum":
+		s.minimum, err = compileNumber(v, kptr)
+
+	case "exclusiveMinimum":
+		s.exclusiveMinimum, err = compileNumber(v, kptr)
+
+	case "maxLength":
+		s.maxLength, err = compileCount(v, kptr)
+
+	case "minLength":
+		s.minLength, err = compileCount(v, kptr)
+
+	case "pattern":
+		s.pattern, err = compilePattern(v, kptr)
+
+	case "prefixItems":
+		s.prefixItems, err = c.compileArray(v, kptr)
+
+	case "items":
+		s.items, err = c.compile(v, kptr)
+
+	case "contains":
+		s.contains, err = c.compile(v, kptr)
+
+	case "maxItems":
+		s.maxItems, err = compileCount(v, kptr)
+
+	case "minItems":
+		s.minItems, err = compileCount(v, kptr)
+
+	case "uniqueItems":
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("%s: must be a boolean", kptr)
+		}
+		s.uniqueItems = b
+
+	case "maxContains":
+		s.maxContains, err = compileCount(v, kptr)
+
+	case "minContains":
+		s.minContains, err = compileCount(v, kptr)
+
+	case "properties":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an object", kptr)
+		}
+		s.properties = make(map[string]*Schema, len(m))
+		for name, p := range m {
+			if s.properties[name], err = c.compile(p, kptr+"/"+escapePointer(name)); err != nil {
+				return err
+			}
+		}
+
+	case "patternProperties":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an object", kptr)
+		}
+		for p, ps := range m {
+			pptr := kptr + "/" + escapePointer(p)
+			re, err := compilePattern(p, pptr)
+			if err != nil {
+				return err
+			}
+			sub, err := c.compile(ps, pptr)
+			if err != nil {
+				return err
+			}
+			s.patternProperties = append(s.patternProperties, patternSchema{re, sub})
+		}
+		// The order of the map is random, so the errors are sorted by pattern.
+		slices.SortFunc(s.patternProperties, func(a, b patternSchema) int {
+			return strings.Compare(a.re.String(), b.re.String())
+		})
+
+	case "additionalProperties":
+		s.additionalProperties, err = c.compile(v, kptr)
+
+	case "propertyNames":
+		s.propertyNames, err = c.compile(v, kptr)
+
+	case "maxProperties":
+		s.maxProperties, err = compileCount(v, kptr)
+
+	case "minProperties":
+		s.minProperties, err = compileCount(v, kptr)
+
+	case "required":
+		s.required, err = compileStrings(v, kptr)
+
+	case "dependentRequired":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an object", kptr)
+		}
+		s.dependentRequired = make(map[string][]string, len(m))
+		for name, d := range m {
+			if s.dependentRequired[name], err = compileStrings(d, kptr+"/"+escapePointer(name)); err != nil {
+				return err
+			}
+		}
+
+	case "allOf":
+		s.allOf, err = c.compileArray(v, kptr)
+
+	case "anyOf":
+		s.anyOf, err = c.compileArray(v, kptr)
+
+	case "oneOf":
+		s.oneOf, err = c.compileArray(v, kptr)
+
+	case "not":
+		s.not, err = c.compile(v, kptr)
+
+	case "if":
+		s.ifs, err = c.compile(v, kptr)
+
+	case "then":
+		s.then, err = c.compile(v, kptr)
+
+	case "else":
+		s.elses, err = c.compile(v, kptr)
+
+	case "$dynamicRef", "$recursiveRef", "unevaluatedItems", "unevaluatedProperties",
+		"dependentSchemas":
+		return fmt.Errorf("%s: unsupported keyword", kptr)
+	}
+
+	return err
+}
+
+// checkCycles reports a cycle of $ref through the subschemas which apply to
+// the same instance, such as {"$ref": "#"}, since the evaluation would never
+// end.
+func (c *compiler) checkCycles() error {
+	const (
+		visiting = iota + 1
+		done
+	)
+
+	ptrs := make(map[*Schema]string, len(c.byPointer))
+	for ptr, s := range c.byPointer {
+		ptrs[s] = ptr
+	}
+
+	state := make(map[*Schema]int, len(c.byPointer))
+
+	var visit func(s *Schema) error
+	visit = func(s *Schema) error {
+		switch state[s] {
+		case visiting:
+			return fmt.Errorf("%s: $ref cycle", pointerOrRoot(ptrs[s]))
+		case done:
+			return nil
+		}
+
+		state[s] = visiting
+		for _, sub := range s.inPlace() {
+			if err := visit(sub); err != nil {
+				return err
+			}
+		}
+		state[s] = done
+
+		return nil
+	}
+
+	for _, ptr := range slices.Sorted(maps.Keys(c.byPointer)) {
+		if err := visit(c.byPointer[ptr]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inPlace returns the subschemas which s applies to the same instance as s.
+func (s *Schema) inPlace() []*Schema {
+	var ret []*Schema
+
+	if s.resolved != nil {
+		ret = append(ret, s.resolved)
+	}
+	ret = append(ret, s.allOf...)
+	ret = append(ret, s.anyOf...)
+	ret = append(ret, s.oneOf...)
+
+	for _, sub := range []*Schema{s.not, s.ifs, s.then, s.elses} {
+		if sub != nil {
+			ret = append(ret, sub)
+		}
+	}
+
+	return ret
+}
+
+func (c *compiler) compileArray(v any, ptr string) ([]*Schema, error) {
+	a, ok := v.([]any)
+	if !ok || len(a) == 0 {
+		return nil, fmt.Errorf("%s: must be a non-empty array", ptr)
+	}
+
+	ret := make([]*Schema, len(a))
+	for i, e := range a {
+		s, err := c.compile(e, ptr+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = s
+	}
+
+	return ret, nil
+}
+
+func compileNumber(v any, ptr string) (*float64, error) {
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a number", ptr)
+	}
+
+	return &f, nil
+}
+
+func compileCount(v any, ptr string) (*int, error) {
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != float64(int(f)) {
+		return nil, fmt.Errorf("%s: must be a non-negative integer", ptr)
+	}
+
+	n := int(f)
+	return &n, nil
+}
+
+func compilePattern(v any, ptr string) (*regexp.Regexp, error) {
+	p, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a string", ptr)
+	}
+
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid pattern: %w", ptr, err)
+	}
+
+	return re, nil
+}
+
+func compileStrings(v any, ptr string) ([]string, error) {
+	a, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be an array of strings", ptr)
+	}
+
+	ret := make([]string, len(a))
+	for i, e := range a {
+		s, ok := e.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: must be an array of strings", ptr)
+		}
+		ret[i] = s
+	}
+
+	return ret, nil
+}
+
+// escapePointer escapes a JSON Pointer reference token (RFC 6901).
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func pointerOrRoot(ptr string) string {
+	if ptr == "" {
+		return "#"
+	}
+
+	return ptr
+}
@@ -0,0 +1,420 @@
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/high-moctane/mocjson-go"
+)
+
+const testSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"name": {"type": "string", "minLength": 1, "maxLength": 8, "pattern": "^[a-z]+$"},
+		"price": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true},
+		"point": {"prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+		"owner": {"$ref": "#/$defs/user"},
+		"status": {"enum": ["active", "deleted"]},
+		"kind": {"oneOf": [{"const": "a"}, {"const": "b"}, {"type": "integer"}]}
+	},
+	"patternProperties": {"^x-": {"type": "string"}},
+	"additionalProperties": false,
+	"required": ["id", "name"],
+	"dependentRequired": {"price": ["tags"]},
+	"$defs": {
+		"user": {
+			"type": "object",
+			"properties": {"email": {"type": "string"}},
+			"required": ["email"],
+			"if": {"properties": {"admin": {"const": true}}, "required": ["admin"]},
+			"then": {"required": ["level"]}
+		}
+	}
+}`
+
+type testError struct {
+	inst, sch string
+}
+
+func TestSchema_Validate(t *testing.T) {
+	t.Parallel()
+
+	s, err := Compile(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		b    []byte
+		want []testError
+	}{
+		{
+			name: "valid",
+			b: []byte(
+				`{"id":1,"name":"abc","price":1.23,"tags":["a","b"],"point":[1,2],"owner":{"email":"e","admin":true,"level":1},"status":"active","kind":"a","x-trace":"t"}`,
+			),
+		},
+		{
+			name: "type",
+			b:    []byte(`[]`),
+			want: []testError{{"", "/type"}},
+		},
+		{
+			name: "number keywords",
+			b:    []byte(`{"id":0.5,"name":"a","price":0.001,"tags":[]}`),
+			want: []testError{
+				{"/id", "/properties/id/type"},
+				{"/id", "/properties/id/minimum"},
+				{"/price", "/properties/price/multipleOf"},
+			},
+		},
+		{
+			name: "string keywords",
+			b:    []byte(`{"id":1,"name":"ABCDEFGHI"}`),
+			want: []testError{
+				{"/name", "/properties/name/maxLength"},
+				{"/name", "/properties/name/pattern"},
+			},
+		},
+		{
+			name: "array keywords",
+			b:    []byte(`{"id":1,"name":"a","tags":["a","a",1],"point":[1,2,3]}`),
+			want: []testError{
+				{"/tags/2", "/properties/tags/items/type"},
+				{"/tags", "/properties/tags/maxItems"},
+				{"/tags", "/properties/tags/uniqueItems"},
+				{"/point/2", "/properties/point/items"},
+			},
+		},
+		{
+			name: "object keywords",
+			b:    []byte(`{"id":1,"name":"a","price":1,"x-a":1,"unknown~/":true}`),
+			want: []testError{
+				{"", "/dependentRequired/price"},
+				{"/x-a", "/patternProperties/^x-/type"},
+				{"/unknown~0~1", "/additionalProperties"},
+			},
+		},
+		{
+			name: "ref and if then",
+			b:    []byte(`{"id":1,"name":"a","owner":{"admin":true}}`),
+			want: []testError{
+				{"/owner", "/properties/owner/$ref/required"},
+				{"/owner", "/properties/owner/$ref/then/required"},
+			},
+		},
+		{
+			name: "enum and oneOf",
+			b:    []byte(`{"id":1,"name":"a","status":"unknown","kind":"c"}`),
+			want: []testError{
+				{"/status", "/properties/status/enum"},
+				{"/kind", "/properties/kind/oneOf"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := mocjson.NewParser(bytes.NewReader(tt.b))
+			v, err := pa.ParseValue()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checkErrors(t, s.Validate(v), tt.want)
+		})
+
+		t.Run(tt.name+" (ValidateParser)", func(t *testing.T) {
+			t.Parallel()
+
+			pa := mocjson.NewParser(bytes.NewReader(tt.b))
+			checkErrors(t, s.ValidateParser(&pa), tt.want)
+
+			if !pa.Lexer().ExpectEOF() {
+				t.Errorf("value is not consumed")
+			}
+		})
+	}
+}
+
+func checkErrors(t *testing.T, err error, want []testError) {
+	t.Helper()
+
+	if want == nil {
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		return
+	}
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("got %v, want ValidationError", err)
+	}
+
+	var got []testError
+	for _, e := range ve.Errors {
+		got = append(got, testError{e.InstancePath, e.SchemaPath})
+	}
+
+	cmp := func(a, b testError) int {
+		return strings.Compare(a.inst+"\x00"+a.sch, b.inst+"\x00"+b.sch)
+	}
+	slices.SortFunc(got, cmp)
+	want = slices.Clone(want)
+	slices.SortFunc(want, cmp)
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSchema_ValidateParser_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	s, err := Compile(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range []string{`{"id":1,`, `{"tags":[1 2]}`, `{"x-a" 1}`} {
+		pa := mocjson.NewParser(strings.NewReader(b))
+
+		var ve *ValidationError
+		if err := s.ValidateParser(&pa); err == nil || errors.As(err, &ve) {
+			t.Errorf("%s: got %v, want syntax error", b, err)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr bool
+	}{
+		{
+			name:   "true",
+			schema: `true`,
+		},
+		{
+			name:   "empty",
+			schema: `{}`,
+		},
+		{
+			name:   "ref to root",
+			schema: `{"properties": {"next": {"$ref": "#"}}}`,
+		},
+		{
+			name:    "not a schema",
+			schema:  `1`,
+			wantErr: true,
+		},
+		{
+			name:    "trailing value",
+			schema:  `{} {}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			schema:  `{"type": "int"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			schema:  `{"pattern": "("}`,
+			wantErr: true,
+		},
+		{
+			name:    "negative minLength",
+			schema:  `{"minLength": -1}`,
+			wantErr: true,
+		},
+		{
+			name:    "unresolvable ref",
+			schema:  `{"$ref": "#/$defs/none"}`,
+			wantErr: true,
+		},
+		{
+			name:    "remote ref",
+			schema:  `{"$ref": "https://example.com/schema"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported keyword",
+			schema:  `{"unevaluatedProperties": false}`,
+			wantErr: true,
+		},
+		{
+			name:    "ref to itself",
+			schema:  `{"$ref": "#"}`,
+			wantErr: true,
+		},
+		{
+			name:    "ref cycle",
+			schema:  `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"allOf": [{"$ref": "#/$defs/a"}]}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "ref cycle through not",
+			schema:  `{"type": "object", "not": {"anyOf": [{"$ref": "#"}]}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Compile(strings.NewReader(tt.schema))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchema_Validate_Recursive(t *testing.T) {
+	t.Parallel()
+
+	s, err := Compile(strings.NewReader(
+		`{"type": "object", "properties": {"next": {"$ref": "#"}}, "additionalProperties": false}`,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pa := mocjson.NewParser(strings.NewReader(`{"next":{"next":{"value":1}}}`))
+	checkErrors(t, s.ValidateParser(&pa), []testError{
+		{"/next/next/value", "/properties/next/$ref/properties/next/$ref/additionalProperties"},
+	})
+}
+
+func TestSchema_Validate_DuplicateKeys(t *testing.T) {
+	t.Parallel()
+
+	s, err := Compile(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		b    string
+		want []testError
+	}{
+		{
+			name: "last one is valid",
+			b:    `{"id":0,"name":"abc","id":1}`,
+		},
+		{
+			name: "last one is invalid",
+			b:    `{"id":1,"name":"abc","id":0}`,
+			want: []testError{{"/id", "/properties/id/minimum"}},
+		},
+		{
+			name: "nested",
+			b:    `{"id":1,"name":"abc","owner":{"email":1,"email":"e"},"owner":{}}`,
+			want: []testError{{"/owner", "/properties/owner/$ref/required"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := mocjson.NewParser(strings.NewReader(tt.b))
+			v, err := pa.ParseValue()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkErrors(t, s.Validate(v), tt.want)
+
+			pa = mocjson.NewParser(strings.NewReader(tt.b))
+			checkErrors(t, s.ValidateParser(&pa), tt.want)
+		})
+	}
+}
+
+func TestSchema_ValidateParser_Order(t *testing.T) {
+	t.Parallel()
+
+	s, err := Compile(strings.NewReader(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []string{
+		`{"name":"ABC","id":0}`,
+		`{"x-b":1,"x-a":2,"owner":{"email":1},"kind":true,"id":0,"name":""}`,
+		`{"name":"","id":0,"name":"ABC"}`,
+	}
+
+	for _, b := range tests {
+		t.Run(b, func(t *testing.T) {
+			t.Parallel()
+
+			pa := mocjson.NewParser(strings.NewReader(b))
+			v, err := pa.ParseValue()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want *ValidationError
+			if !errors.As(s.Validate(v), &want) {
+				t.Fatal("want ValidationError")
+			}
+
+			pa = mocjson.NewParser(strings.NewReader(b))
+			var got *ValidationError
+			if !errors.As(s.ValidateParser(&pa), &got) {
+				t.Fatal("want ValidationError")
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkSchema_Validate(b *testing.B) {
+	s, err := Compile(strings.NewReader(testSchema))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	bs := []byte(
+		`{"id":1,"name":"abc","price":1.23,"tags":["a","b"],"point":[1,2],"owner":{"email":"e"},"status":"active","kind":2}`,
+	)
+
+	b.Run("Validate", func(b *testing.B) {
+		for b.Loop() {
+			pa := mocjson.NewParser(bytes.NewReader(bs))
+			v, _ := pa.ParseValue()
+			if err := s.Validate(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("ValidateParser", func(b *testing.B) {
+		for b.Loop() {
+			pa := mocjson.NewParser(bytes.NewReader(bs))
+			if err := s.ValidateParser(&pa); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
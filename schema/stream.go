@@ -0,0 +1,209 @@
+package schema
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/high-moctane/mocjson-go"
+)
+
+// ValidateParser reads the next value from pa and validates it. Objects and
+// arrays are read token by token from the Lexer without being built, except
+// that the values under enum, const, allOf, anyOf, oneOf, not, if, contains
+// and uniqueItems are built with ParseValue, since they are evaluated more
+// than once. The last one of duplicate keys wins as in ParseValue, and the
+// members are checked in the order of the sorted keys as in Validate, so the
+// result is the same as Validate of the output of ParseValue, including the
+// order of the errors.
+//
+// It returns a *ValidationError if the value is invalid, and another error if
+// the input is not a valid JSON value.
+func (s *Schema) ValidateParser(pa *mocjson.Parser) error {
+	errs, err := s.stream(pa, "", "")
+	if err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// needsValue reports whether s needs the whole value to be evaluated.
+func (s *Schema) needsValue() bool {
+	if s.boolean != nil {
+		return false
+	}
+
+	hasOthers := s.types != nil || s.multipleOf != nil || s.maximum != nil ||
+		s.exclusiveMaximum != nil || s.minimum != nil || s.exclusiveMinimum != nil ||
+		s.maxLength != nil || s.minLength != nil || s.pattern != nil ||
+		s.prefixItems != nil || s.items != nil || s.maxItems != nil || s.minItems != nil ||
+		s.properties != nil || s.patternProperties != nil || s.additionalProperties != nil ||
+		s.propertyNames != nil || s.maxProperties != nil || s.minProperties != nil ||
+		s.required != nil || s.dependentRequired != nil
+
+	return s.ref != "" && hasOthers ||
+		s.enum != nil || s.hasConst || s.allOf != nil || s.anyOf != nil || s.oneOf != nil ||
+		s.not != nil || s.ifs != nil || s.contains != nil || s.uniqueItems
+}
+
+func (s *Schema) stream(pa *mocjson.Parser, inst, sch string) ([]*Error, error) {
+	lx := pa.Lexer()
+
+	if s.boolean != nil {
+		if _, err := pa.ParseRaw(); err != nil {
+			return nil, err
+		}
+		return s.eval(nil, inst, sch), nil
+	}
+
+	if s.ref != "" && !s.needsValue() {
+		return s.resolved.stream(pa, inst, sch+"/$ref")
+	}
+
+	switch lx.NextTokenType() {
+	case mocjson.TokenTypeBeginObject:
+		if !s.needsValue() {
+			return s.streamObject(pa, inst, sch)
+		}
+
+	case mocjson.TokenTypeBeginArray:
+		if !s.needsValue() {
+			return s.streamArray(pa, inst, sch)
+		}
+	}
+
+	v, err := pa.ParseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.eval(v, inst, sch), nil
+}
+
+func (s *Schema) streamObject(pa *mocjson.Parser, inst, sch string) ([]*Error, error) {
+	lx := pa.Lexer()
+
+	errs := s.checkValue(map[string]any{}, inst, sch)
+
+	if !lx.ExpectBeginObject() {
+		return nil, errors.New("expect begin object")
+	}
+
+	// memberErrs holds the errors of the members. The last one of duplicate
+	// keys wins, as ParseValue does.
+	memberErrs := make(map[string][]*Error)
+
+	// The errors are in the order of the sorted keys as in eval.
+	end := func() []*Error {
+		keys := slices.Sorted(maps.Keys(memberErrs))
+		for _, k := range keys {
+			errs = append(errs, memberErrs[k]...)
+		}
+		return append(errs, s.checkKeys(keys, inst, sch)...)
+	}
+
+	if lx.NextTokenType() == mocjson.TokenTypeEndObject {
+		lx.ExpectEndObject()
+		return end(), nil
+	}
+
+	for {
+		k, ok := lx.ExpectString()
+		if !ok {
+			return nil, errors.New("expect string")
+		}
+		if !lx.ExpectNameSeparator() {
+			return nil, errors.New("expect name separator")
+		}
+
+		kinst := inst + "/" + escapePointer(k)
+
+		var merrs []*Error
+
+		if s.propertyNames != nil {
+			merrs = append(merrs, s.propertyNames.eval(k, kinst, sch+"/propertyNames")...)
+		}
+
+		switch pss := s.propertySchemas(k, sch); len(pss) {
+		case 0:
+			if _, err := pa.ParseRaw(); err != nil {
+				return nil, err
+			}
+
+		case 1:
+			e, err := pss[0].schema.stream(pa, kinst, pss[0].path)
+			if err != nil {
+				return nil, err
+			}
+			merrs = append(merrs, e...)
+
+		default:
+			v, err := pa.ParseValue()
+			if err != nil {
+				return nil, err
+			}
+			for _, ps := range pss {
+				merrs = append(merrs, ps.schema.eval(v, kinst, ps.path)...)
+			}
+		}
+
+		memberErrs[k] = merrs
+
+		switch lx.NextTokenType() {
+		case mocjson.TokenTypeEndObject:
+			lx.ExpectEndObject()
+			return end(), nil
+
+		case mocjson.TokenTypeValueSeparator:
+			lx.ExpectValueSeparator()
+
+		default:
+			return nil, errors.New("expect value separator or end object")
+		}
+	}
+}
+
+func (s *Schema) streamArray(pa *mocjson.Parser, inst, sch string) ([]*Error, error) {
+	lx := pa.Lexer()
+
+	errs := s.checkValue([]any{}, inst, sch)
+
+	if !lx.ExpectBeginArray() {
+		return nil, errors.New("expect begin array")
+	}
+
+	if lx.NextTokenType() == mocjson.TokenTypeEndArray {
+		lx.ExpectEndArray()
+		return append(errs, s.checkItemCount(0, inst, sch)...), nil
+	}
+
+	for i := 0; ; i++ {
+		if is, isch := s.itemSchema(i, sch); is != nil {
+			e, err := is.stream(pa, inst+"/"+strconv.Itoa(i), isch)
+			if err != nil {
+				return nil, err
+			}
+			errs = append(errs, e...)
+		} else if _, err := pa.ParseRaw(); err != nil {
+			return nil, err
+		}
+
+		switch lx.NextTokenType() {
+		case mocjson.TokenTypeEndArray:
+			lx.ExpectEndArray()
+			return append(errs, s.checkItemCount(i+1, inst, sch)...), nil
+
+		case mocjson.TokenTypeValueSeparator:
+			lx.ExpectValueSeparator()
+
+		default:
+			return nil, errors.New("expect value separator or end array")
+		}
+	}
+}
@@ -0,0 +1,392 @@
+package schema
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error is a violation of a schema keyword.
+type Error struct {
+	// InstancePath is the JSON Pointer to the invalid value, e.g. "/items/0".
+	InstancePath string
+	// SchemaPath is the JSON Pointer to the violated keyword, e.g.
+	// "/properties/items/items/type". The keywords of a $ref target follow
+	// "/$ref".
+	SchemaPath string
+	Msg        string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("#%s: %s (schema #%s)", e.InstancePath, e.Msg, e.SchemaPath)
+}
+
+// ValidationError lists all the violations of a value.
+type ValidationError struct {
+	Errors []*Error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Validate validates v, which must be the output of mocjson.Parser.ParseValue.
+// It returns a *ValidationError if v is invalid.
+func (s *Schema) Validate(v any) error {
+	if errs := s.eval(v, "", ""); len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+func (s *Schema) valid(v any) bool {
+	return len(s.eval(v, "", "")) == 0
+}
+
+func (s *Schema) eval(v any, inst, sch string) []*Error {
+	if s.boolean != nil {
+		if !*s.boolean {
+			return []*Error{{inst, sch, "false schema"}}
+		}
+		return nil
+	}
+
+	var errs []*Error
+	add := func(kw, format string, a ...any) {
+		errs = append(errs, &Error{inst, sch + "/" + kw, fmt.Sprintf(format, a...)})
+	}
+
+	if s.ref != "" {
+		errs = append(errs, s.resolved.eval(v, inst, sch+"/$ref")...)
+	}
+
+	errs = append(errs, s.checkValue(v, inst, sch)...)
+
+	switch x := v.(type) {
+	case float64:
+		errs = append(errs, s.checkNumber(x, inst, sch)...)
+
+	case string:
+		errs = append(errs, s.checkString(x, inst, sch)...)
+
+	case []any:
+		for i, e := range x {
+			if is, isch := s.itemSchema(i, sch); is != nil {
+				errs = append(errs, is.eval(e, inst+"/"+strconv.Itoa(i), isch)...)
+			}
+		}
+
+		errs = append(errs, s.checkArray(x, inst, sch)...)
+
+	case map[string]any:
+		keys := slices.Sorted(maps.Keys(x))
+
+		for _, k := range keys {
+			kinst := inst + "/" + escapePointer(k)
+
+			if s.propertyNames != nil {
+				errs = append(errs, s.propertyNames.eval(k, kinst, sch+"/propertyNames")...)
+			}
+
+			for _, ps := range s.propertySchemas(k, sch) {
+				errs = append(errs, ps.schema.eval(x[k], kinst, ps.path)...)
+			}
+		}
+
+		errs = append(errs, s.checkKeys(keys, inst, sch)...)
+	}
+
+	for i, sub := range s.allOf {
+		errs = append(errs, sub.eval(v, inst, sch+"/allOf/"+strconv.Itoa(i))...)
+	}
+
+	if s.anyOf != nil && !slices.ContainsFunc(s.anyOf, func(sub *Schema) bool {
+		return sub.valid(v)
+	}) {
+		add("anyOf", "must be valid against any of the schemas")
+	}
+
+	if s.oneOf != nil {
+		n := 0
+		for _, sub := range s.oneOf {
+			if sub.valid(v) {
+				n++
+			}
+		}
+		if n != 1 {
+			add("oneOf", "must be valid against exactly one of the schemas, but %d", n)
+		}
+	}
+
+	if s.not != nil && s.not.valid(v) {
+		add("not", "must not be valid against the schema")
+	}
+
+	if s.ifs != nil {
+		if s.ifs.valid(v) {
+			if s.then != nil {
+				errs = append(errs, s.then.eval(v, inst, sch+"/then")...)
+			}
+		} else if s.elses != nil {
+			errs = append(errs, s.elses.eval(v, inst, sch+"/else")...)
+		}
+	}
+
+	return errs
+}
+
+// checkValue checks the keywords type, enum and const.
+func (s *Schema) checkValue(v any, inst, sch string) []*Error {
+	var errs []*Error
+
+	if s.types != nil && !slices.ContainsFunc(s.types, func(t string) bool {
+		return hasType(v, t)
+	}) {
+		errs = append(errs, &Error{
+			inst,
+			sch + "/type",
+			fmt.Sprintf("must be %s", strings.Join(s.types, " or ")),
+		})
+	}
+
+	if s.enum != nil && !slices.ContainsFunc(s.enum, func(e any) bool {
+		return reflect.DeepEqual(v, e)
+	}) {
+		errs = append(errs, &Error{inst, sch + "/enum", "must be one of the enum values"})
+	}
+
+	if s.hasConst && !reflect.DeepEqual(v, s.constant) {
+		errs = append(errs, &Error{inst, sch + "/const", "must be the const value"})
+	}
+
+	return errs
+}
+
+func hasType(v any, t string) bool {
+	switch x := v.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case float64:
+		return t == "number" || t == "integer" && x == math.Trunc(x)
+	case string:
+		return t == "string"
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+
+	return false
+}
+
+func (s *Schema) checkNumber(f float64, inst, sch string) []*Error {
+	var errs []*Error
+	add := func(kw, format string, a ...any) {
+		errs = append(errs, &Error{inst, sch + "/" + kw, fmt.Sprintf(format, a...)})
+	}
+
+	if s.multipleOf != nil {
+		q := f / *s.multipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9*math.Max(1, math.Abs(q)) {
+			add("multipleOf", "must be a multiple of %v", *s.multipleOf)
+		}
+	}
+	if s.maximum != nil && f > *s.maximum {
+		add("maximum", "must be less than or equal to %v", *s.maximum)
+	}
+	if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+		add("exclusiveMaximum", "must be less than %v", *s.exclusiveMaximum)
+	}
+	if s.minimum != nil && f < *s.minimum {
+		add("minimum", "must be greater than or equal to %v", *s.minimum)
+	}
+	if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+		add("exclusiveMinimum", "must be greater than %v", *s.exclusiveMinimum)
+	}
+
+	return errs
+}
+
+func (s *Schema) checkString(str, inst, sch string) []*Error {
+	var errs []*Error
+	add := func(kw, format string, a ...any) {
+		errs = append(errs, &Error{inst, sch + "/" + kw, fmt.Sprintf(format, a...)})
+	}
+
+	n := utf8.RuneCountInString(str)
+	if s.maxLength != nil && n > *s.maxLength {
+		add("maxLength", "must be at most %d characters", *s.maxLength)
+	}
+	if s.minLength != nil && n < *s.minLength {
+		add("minLength", "must be at least %d characters", *s.minLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		add("pattern", "must match %s", s.pattern)
+	}
+
+	return errs
+}
+
+// itemSchema returns the schema of the i-th item and its path.
+func (s *Schema) itemSchema(i int, sch string) (*Schema, string) {
+	if i < len(s.prefixItems) {
+		return s.prefixItems[i], sch + "/prefixItems/" + strconv.Itoa(i)
+	}
+	if s.items != nil {
+		return s.items, sch + "/items"
+	}
+
+	return nil, ""
+}
+
+// checkArray checks the keywords about the array as a whole.
+func (s *Schema) checkArray(a []any, inst, sch string) []*Error {
+	var errs []*Error
+	add := func(kw, format string, args ...any) {
+		errs = append(errs, &Error{inst, sch + "/" + kw, fmt.Sprintf(format, args...)})
+	}
+
+	errs = append(errs, s.checkItemCount(len(a), inst, sch)...)
+
+	if s.uniqueItems {
+		for i := range a {
+			if slices.ContainsFunc(a[:i], func(e any) bool {
+				return reflect.DeepEqual(a[i], e)
+			}) {
+				add("uniqueItems", "must have unique items, but %d is a duplicate", i)
+				break
+			}
+		}
+	}
+
+	if s.contains != nil {
+		n := 0
+		for _, e := range a {
+			if s.contains.valid(e) {
+				n++
+			}
+		}
+
+		minContains := 1
+		if s.minContains != nil {
+			minContains = *s.minContains
+		}
+
+		if n < minContains {
+			kw := "contains"
+			if s.minContains != nil {
+				kw = "minContains"
+			}
+			add(kw, "must contain at least %d matching items, but %d", minContains, n)
+		}
+		if s.maxContains != nil && n > *s.maxContains {
+			add("maxContains", "must contain at most %d matching items, but %d", *s.maxContains, n)
+		}
+	}
+
+	return errs
+}
+
+func (s *Schema) checkItemCount(n int, inst, sch string) []*Error {
+	var errs []*Error
+
+	if s.maxItems != nil && n > *s.maxItems {
+		errs = append(errs, &Error{
+			inst,
+			sch + "/maxItems",
+			fmt.Sprintf("must have at most %d items", *s.maxItems),
+		})
+	}
+	if s.minItems != nil && n < *s.minItems {
+		errs = append(errs, &Error{
+			inst,
+			sch + "/minItems",
+			fmt.Sprintf("must have at least %d items", *s.minItems),
+		})
+	}
+
+	return errs
+}
+
+type pathSchema struct {
+	schema *Schema
+	path   string
+}
+
+// propertySchemas returns the schemas which apply to the property k: the
+// schema in properties, the schemas in patternProperties which match k, or
+// additionalProperties if none of them apply.
+func (s *Schema) propertySchemas(k, sch string) []pathSchema {
+	var ret []pathSchema
+
+	if p, ok := s.properties[k]; ok {
+		ret = append(ret, pathSchema{p, sch + "/properties/" + escapePointer(k)})
+	}
+
+	for _, pp := range s.patternProperties {
+		if pp.re.MatchString(k) {
+			ret = append(ret, pathSchema{
+				pp.schema,
+				sch + "/patternProperties/" + escapePointer(pp.re.String()),
+			})
+		}
+	}
+
+	if ret == nil && s.additionalProperties != nil {
+		ret = append(ret, pathSchema{s.additionalProperties, sch + "/additionalProperties"})
+	}
+
+	return ret
+}
+
+// checkKeys checks the keywords about the set of the keys of an object.
+func (s *Schema) checkKeys(keys []string, inst, sch string) []*Error {
+	var errs []*Error
+	add := func(kw, format string, a ...any) {
+		errs = append(errs, &Error{inst, sch + "/" + kw, fmt.Sprintf(format, a...)})
+	}
+
+	if s.maxProperties != nil && len(keys) > *s.maxProperties {
+		add("maxProperties", "must have at most %d properties", *s.maxProperties)
+	}
+	if s.minProperties != nil && len(keys) < *s.minProperties {
+		add("minProperties", "must have at least %d properties", *s.minProperties)
+	}
+
+	for _, r := range s.required {
+		if !slices.Contains(keys, r) {
+			add("required", "missing property %q", r)
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(s.dependentRequired)) {
+		if !slices.Contains(keys, k) {
+			continue
+		}
+		for _, r := range s.dependentRequired[k] {
+			if !slices.Contains(keys, r) {
+				add(
+					"dependentRequired/"+escapePointer(k),
+					"missing property %q required by %q",
+					r,
+					k,
+				)
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,225 @@
+package mocjson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// recordSeparator precedes each JSON text in RFC 7464 sequences.
+const recordSeparator = 0x1e
+
+// SequenceMode is the framing of the values read by SequenceReader.
+type SequenceMode int
+
+const (
+	// SequenceConcatenated reads values back to back, such as `{}{}[]`, with
+	// optional white spaces between them. Newline-delimited JSON is read in
+	// this mode too.
+	SequenceConcatenated SequenceMode = iota
+
+	// SequenceRFC7464 reads JSON text sequences (RFC 7464), where each value
+	// is preceded by the record separator 0x1E.
+	SequenceRFC7464
+)
+
+// SequenceReader reads a sequence of JSON values. A malformed record does not
+// fail the whole stream: the reader reports it and resynchronises at the next
+// record.
+//
+// In SequenceConcatenated mode, the next record after a malformed one starts
+// after the next line break, or at the next '{' or '[' which directly follows
+// '}' or ']', whichever comes first. In SequenceRFC7464 mode, it starts at the
+// next record separator.
+type SequenceReader struct {
+	pa   Parser
+	mode SequenceMode
+}
+
+func NewSequenceReader(r io.Reader, mode SequenceMode) SequenceReader {
+	return SequenceReader{pa: NewParser(r), mode: mode}
+}
+
+// RecordError is returned for a malformed record. The reader has skipped the
+// record, so the next call of Next continues with the following one.
+type RecordError struct {
+	// Offset is the byte offset of the record in the input.
+	Offset int64
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Next reads the next value. It returns a *RecordError for a malformed record,
+// io.EOF at the end of the input, and the error of the underlying reader as
+// it is.
+func (sr *SequenceReader) Next() (RawValue, error) {
+	if sr.mode == SequenceRFC7464 {
+		return sr.nextRFC7464()
+	}
+
+	return sr.nextConcatenated()
+}
+
+// All iterates over the values. It yields the *RecordError of a malformed
+// record and continues, and stops at the end of the input or after yielding
+// the error of the underlying reader.
+func (sr *SequenceReader) All() iter.Seq2[RawValue, error] {
+	return func(yield func(RawValue, error) bool) {
+		for {
+			v, err := sr.Next()
+			if err == io.EOF {
+				return
+			}
+
+			var re *RecordError
+			if !yield(v, err) || err != nil && !errors.As(err, &re) {
+				return
+			}
+		}
+	}
+}
+
+func (sr *SequenceReader) nextConcatenated() (RawValue, error) {
+	lx := &sr.pa.lx
+
+	if lx.ExpectEOF() {
+		return nil, io.EOF
+	}
+	if err := sr.readErr(); err != nil {
+		return nil, err
+	}
+
+	off := lx.sc.off
+
+	v, err := sr.pa.ParseRaw()
+	if err != nil {
+		if err := sr.readErr(); err != nil {
+			return nil, err
+		}
+		sr.resyncConcatenated()
+		return nil, &RecordError{Offset: off, Err: err}
+	}
+
+	return v, nil
+}
+
+// resyncConcatenated skips the input up to the start of the next record.
+func (sr *SequenceReader) resyncConcatenated() {
+	sc := &sr.pa.lx.sc
+	var prev byte
+
+	for sc.Load() {
+		b := sc.Peek()
+
+		switch {
+		case b == '\n':
+			sc.Skip(1)
+			return
+
+		case (b == '{' || b == '[') && (prev == '}' || prev == ']'):
+			return
+
+		case b != ' ' && b != '\t' && b != '\r':
+			prev = b
+		}
+
+		sc.Skip(1)
+	}
+}
+
+func (sr *SequenceReader) nextRFC7464() (RawValue, error) {
+	lx := &sr.pa.lx
+	sc := &lx.sc
+
+	if lx.ExpectEOF() {
+		return nil, io.EOF
+	}
+	if err := sr.readErr(); err != nil {
+		return nil, err
+	}
+
+	off := sc.off
+
+	if sc.Peek() != recordSeparator {
+		sr.resyncRFC7464()
+		return nil, &RecordError{Offset: off, Err: errors.New("expect record separator")}
+	}
+
+	// Empty records are ignored.
+	for sc.Load() && sc.Peek() == recordSeparator {
+		sc.Skip(1)
+		off = sc.off
+	}
+
+	if lx.ExpectEOF() {
+		return nil, io.EOF
+	}
+
+	v, err := sr.pa.ParseRaw()
+	if err != nil {
+		if err := sr.readErr(); err != nil {
+			return nil, err
+		}
+		sr.resyncRFC7464()
+		return nil, &RecordError{Offset: off, Err: err}
+	}
+
+	// A number, true, false or null which is not followed by a white space may
+	// be truncated.
+	if !bytes.ContainsAny(v[:1], `{["`) && (!sc.Load() || sc.CountWhiteSpace() == 0) {
+		if err := sr.readErr(); err != nil {
+			return nil, err
+		}
+		sr.resyncRFC7464()
+		return nil, &RecordError{Offset: off, Err: errors.New("truncated value")}
+	}
+
+	lx.skipWhiteSpaces()
+	if sc.Load() && sc.Peek() != recordSeparator {
+		sr.resyncRFC7464()
+		return nil, &RecordError{Offset: off, Err: errors.New("expect record separator")}
+	}
+	if err := sr.readErr(); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// resyncRFC7464 skips the input up to the next record separator.
+func (sr *SequenceReader) resyncRFC7464() {
+	sc := &sr.pa.lx.sc
+
+	for sc.Load() {
+		buf := sc.PeekN(sc.BufferedLen())
+		if i := bytes.IndexByte(buf, recordSeparator); i >= 0 {
+			sc.Skip(i)
+			return
+		}
+		sc.Skip(len(buf))
+	}
+}
+
+// readErr returns the error of the underlying reader other than io.EOF once
+// the buffered input is exhausted.
+func (sr *SequenceReader) readErr() error {
+	sc := &sr.pa.lx.sc
+
+	if sc.Load() {
+		return nil
+	}
+	if err := sc.Err(); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
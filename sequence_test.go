@@ -0,0 +1,178 @@
+package mocjson
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSequenceReader_Next(t *testing.T) {
+	t.Parallel()
+
+	// "!" stands for a *RecordError.
+	tests := []struct {
+		name string
+		mode SequenceMode
+		b    string
+		want []string
+	}{
+		{
+			name: "concatenated",
+			mode: SequenceConcatenated,
+			b:    `{}{}[]1 "a"true null{"a":[1]}`,
+			want: []string{`{}`, `{}`, `[]`, `1`, `"a"`, `true`, `null`, `{"a":[1]}`},
+		},
+		{
+			name: "newline delimited",
+			mode: SequenceConcatenated,
+			b:    "{\"a\":1}\n\n {\"b\":2} \r\n",
+			want: []string{`{"a":1}`, `{"b":2}`},
+		},
+		{
+			name: "empty",
+			mode: SequenceConcatenated,
+			b:    " \n ",
+			want: nil,
+		},
+		{
+			name: "malformed packed record",
+			mode: SequenceConcatenated,
+			b:    `{"a":x,"b":{"c":1}}{"d":1}[1}[2]`,
+			want: []string{"!", `{"d":1}`, "!", `[2]`},
+		},
+		{
+			name: "malformed line",
+			mode: SequenceConcatenated,
+			b:    "{\"a\":1}\n{\"b\":[1,}\n{\"c\":3}\n",
+			want: []string{`{"a":1}`, "!", `{"c":3}`},
+		},
+		{
+			name: "truncated at end",
+			mode: SequenceConcatenated,
+			b:    `{"a":1}{"b":`,
+			want: []string{`{"a":1}`, "!"},
+		},
+		{
+			name: "rfc7464",
+			mode: SequenceRFC7464,
+			b:    "\x1e{}\n\x1e[1]\n\x1e\"a\"\n\x1e1\n\x1etrue\n",
+			want: []string{`{}`, `[1]`, `"a"`, `1`, `true`},
+		},
+		{
+			name: "rfc7464 empty records",
+			mode: SequenceRFC7464,
+			b:    "\x1e\x1e\x1e{}\n\x1e",
+			want: []string{`{}`},
+		},
+		{
+			name: "rfc7464 truncated number",
+			mode: SequenceRFC7464,
+			b:    "\x1e123\x1e{}\n\x1e45",
+			want: []string{"!", `{}`, "!"},
+		},
+		{
+			name: "rfc7464 garbage before separator",
+			mode: SequenceRFC7464,
+			b:    "x\x1e{}\n",
+			want: []string{"!", `{}`},
+		},
+		{
+			name: "rfc7464 malformed record",
+			mode: SequenceRFC7464,
+			b:    "\x1e{\"a\":\n\x1e{} x\n\x1e[]\n",
+			want: []string{"!", "!", `[]`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sr := NewSequenceReader(strings.NewReader(tt.b), tt.mode)
+
+			var got []string
+			for {
+				v, err := sr.Next()
+				if err == io.EOF {
+					break
+				}
+
+				var re *RecordError
+				switch {
+				case errors.As(err, &re):
+					got = append(got, "!")
+				case err != nil:
+					t.Fatal(err)
+				default:
+					got = append(got, string(v))
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequenceReader_All(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read error")
+	r := io.MultiReader(strings.NewReader(`{}x[]`), iotest.ErrReader(errRead))
+	sr := NewSequenceReader(r, SequenceConcatenated)
+
+	var got []string
+	var gotErr error
+	for v, err := range sr.All() {
+		var re *RecordError
+		switch {
+		case errors.As(err, &re):
+			got = append(got, "!")
+		case err != nil:
+			gotErr = err
+		default:
+			got = append(got, string(v))
+		}
+	}
+
+	if want := []string{`{}`, "!"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if gotErr != errRead {
+		t.Errorf("got %v, want %v", gotErr, errRead)
+	}
+}
+
+func BenchmarkSequenceReader_Next(b *testing.B) {
+	for _, mode := range []struct {
+		name string
+		mode SequenceMode
+		sep  string
+	}{
+		{"concatenated", SequenceConcatenated, ""},
+		{"rfc7464", SequenceRFC7464, "\x1e"},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			s := strings.Repeat(mode.sep+`{"id":"a","kind":"deleted"}`+"\n", 1000)
+			r := strings.NewReader(s)
+
+			for b.Loop() {
+				r.Reset(s)
+				sr := NewSequenceReader(r, mode.mode)
+				for {
+					_, err := sr.Next()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,453 @@
+package mocjson
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type structInfo struct {
+	fields []fieldInfo
+	// byName maps the names and the aliases to the index of fields.
+	byName map[string]int
+	// nocase is the indices of fields with the nocase option.
+	nocase []int
+}
+
+type fieldInfo struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+	optional  bool
+
+	// aliases is set by the mocjson:"alias=<name>|..." option.
+	aliases []string
+	// nocase is set by the mocjson:"nocase" option.
+	nocase bool
+	// defaultValue is set by the mocjson:"default=<json>" option.
+	defaultValue RawValue
+	// rules is set by the validation options.
+	rules *rules
+
+	// timeLayout is set by the time=<format> or <format> option.
+	timeLayout string
+	// duration is set by the duration option.
+	duration bool
+}
+
+var structInfoCache sync.Map // map[reflect.Type]structInfoResult
+
+type structInfoResult struct {
+	si  *structInfo
+	err error
+}
+
+func cachedStructInfo(t reflect.Type) (*structInfo, error) {
+	if r, ok := structInfoCache.Load(t); ok {
+		return r.(structInfoResult).si, r.(structInfoResult).err
+	}
+
+	si, err := newStructInfo(t)
+	r, _ := structInfoCache.LoadOrStore(t, structInfoResult{si: si, err: err})
+	return r.(structInfoResult).si, r.(structInfoResult).err
+}
+
+// newStructInfo collects the fields of t. Fields of anonymous struct fields
+// without json names are promoted as encoding/json does: among the fields with
+// the same name, the shallowest one wins, a tagged one wins over untagged ones
+// at the same depth, and the others at the same depth conflict and are all
+// dropped.
+func newStructInfo(t reflect.Type) (*structInfo, error) {
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+
+	var fields []fieldInfo
+
+	next := []embedded{{t: t}}
+	visited := make(map[reflect.Type]bool)
+
+	for len(next) > 0 {
+		current := next
+		next = nil
+
+		for _, e := range current {
+			if visited[e.t] {
+				continue
+			}
+
+			for i := range e.t.NumField() {
+				sf := e.t.Field(i)
+				ft := sf.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+
+				if sf.Anonymous {
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(slices.Clone(e.index), i)
+
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, embedded{t: ft, index: index})
+					continue
+				}
+
+				f, err := newFieldInfo(sf, name, opts, index)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, f)
+			}
+		}
+
+		// A type embedded twice at the same depth makes its fields conflict.
+		for _, e := range current {
+			visited[e.t] = true
+		}
+	}
+
+	si := &structInfo{byName: make(map[string]int)}
+
+	slices.SortStableFunc(fields, func(a, b fieldInfo) int {
+		return cmp.Compare(a.name, b.name)
+	})
+
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+
+		if f, ok := dominantField(fields[i:j]); ok {
+			si.fields = append(si.fields, f)
+		}
+		i = j
+	}
+
+	slices.SortFunc(si.fields, func(a, b fieldInfo) int {
+		return slices.Compare(a.index, b.index)
+	})
+
+	for i := range si.fields {
+		si.byName[si.fields[i].name] = i
+	}
+
+	for i, f := range si.fields {
+		for _, a := range f.aliases {
+			if _, ok := si.byName[a]; ok {
+				return nil, fmt.Errorf("field %s: alias %q conflicts", f.name, a)
+			}
+			si.byName[a] = i
+		}
+
+		if f.nocase {
+			si.nocase = append(si.nocase, i)
+		}
+	}
+
+	return si, nil
+}
+
+// lookup returns the index of the field whose name or alias is k. The exact
+// match is tried first, and then the case-insensitive match for the fields
+// with the nocase option.
+func (si *structInfo) lookup(k string) (int, bool) {
+	if i, ok := si.byName[k]; ok {
+		return i, true
+	}
+
+	for _, i := range si.nocase {
+		f := &si.fields[i]
+		if strings.EqualFold(k, f.name) {
+			return i, true
+		}
+		for _, a := range f.aliases {
+			if strings.EqualFold(k, a) {
+				return i, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+func dominantField(fields []fieldInfo) (fieldInfo, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields {
+		depth = min(depth, len(f.index))
+	}
+
+	var (
+		ret       fieldInfo
+		found     bool
+		tagged    bool
+		ambiguous bool
+	)
+
+	for _, f := range fields {
+		if len(f.index) != depth {
+			continue
+		}
+
+		switch {
+		case !found:
+			ret, found, tagged = f, true, f.tagged
+		case f.tagged && !tagged:
+			ret, tagged, ambiguous = f, true, false
+		case f.tagged == tagged:
+			ambiguous = true
+		}
+	}
+
+	return ret, found && !ambiguous
+}
+
+func newFieldInfo(sf reflect.StructField, name, opts string, index []int) (fieldInfo, error) {
+	f := fieldInfo{
+		name:   name,
+		index:  index,
+		tagged: name != "",
+	}
+	if f.name == "" {
+		f.name = sf.Name
+	}
+
+	_, f.omitEmpty = lookupTagOption(opts, "omitempty")
+	f.optional = f.omitEmpty
+
+	// The format may be given as time=<format> or as the bare <format>.
+	var formats []string
+	if v, ok := lookupTagOption(opts, "time"); ok {
+		formats = append(formats, v)
+	}
+	for o := range strings.SplitSeq(opts, ",") {
+		if _, ok := timeLayouts[o]; ok {
+			formats = append(formats, o)
+		}
+	}
+	if len(formats) > 1 {
+		return fieldInfo{}, fmt.Errorf("field %s: multiple time formats %q", sf.Name, formats)
+	}
+
+	if len(formats) == 1 {
+		layout, ok := timeLayouts[formats[0]]
+		if !ok {
+			return fieldInfo{}, fmt.Errorf("field %s: unknown time format %q", sf.Name, formats[0])
+		}
+		if indirectType(sf.Type) != timeType {
+			return fieldInfo{}, fmt.Errorf("field %s: time option on %v", sf.Name, sf.Type)
+		}
+		f.timeLayout = layout
+	}
+
+	if _, ok := lookupTagOption(opts, "duration"); ok {
+		if indirectType(sf.Type) != durationType {
+			return fieldInfo{}, fmt.Errorf("field %s: duration option on %v", sf.Name, sf.Type)
+		}
+		f.duration = true
+	}
+
+	mopts, err := ParseMocJSONTag(sf.Tag.Get("mocjson"))
+	if err != nil {
+		return fieldInfo{}, fmt.Errorf("field %s: %w", sf.Name, err)
+	}
+
+	if v, ok := mopts["alias"]; ok {
+		aliases := strings.Split(v, "|")
+		if slices.Contains(aliases, "") {
+			return fieldInfo{}, fmt.Errorf("field %s: empty alias", sf.Name)
+		}
+		f.aliases = aliases
+	}
+
+	_, f.nocase = mopts["nocase"]
+
+	r, err := newRules(sf, mopts)
+	if err != nil {
+		return fieldInfo{}, err
+	}
+	f.rules = r
+
+	if v, ok := mopts["default"]; ok {
+		f.defaultValue = RawValue(v)
+		f.optional = true
+	}
+
+	return f, nil
+}
+
+func (si *structInfo) validate(seen []bool) error {
+	for i := range si.fields {
+		if !seen[i] && !si.fields[i].optional {
+			return fmt.Errorf("missing %s", si.fields[i].name)
+		}
+	}
+
+	return nil
+}
+
+// fieldByIndexAlloc is like reflect.Value.FieldByIndex, but it allocates the
+// nil embedded pointers on the way.
+func fieldByIndexAlloc(rv reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, fmt.Errorf(
+						"cannot set embedded pointer to unexported struct: %v",
+						rv.Type().Elem(),
+					)
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+
+	return rv, nil
+}
+
+// fieldByIndexNoAlloc is like reflect.Value.FieldByIndex, but it reports false
+// instead of panicking on the nil embedded pointers.
+func fieldByIndexNoAlloc(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+
+	return rv, true
+}
+
+// lookupTagOption looks up the option "key" or "key=value" in the
+// comma-separated options.
+func lookupTagOption(opts, key string) (string, bool) {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+
+		k, v, _ := strings.Cut(o, "=")
+		if k == key {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+// mocJSONTagOptions reports whether each option of the mocjson tag takes a
+// value.
+var mocJSONTagOptions = map[string]bool{
+	"alias":     true,
+	"nocase":    false,
+	"default":   true,
+	"min":       true,
+	"max":       true,
+	"minLength": true,
+	"maxLength": true,
+	"pattern":   true,
+	"enum":      true,
+	"nonempty":  false,
+}
+
+// ParseMocJSONTag parses the mocjson tag into the options. The options are
+// separated by "," and an option is "key" or "key=value". The values of alias
+// and enum are separated by "|", as in "alias=user_id|uid". The value of
+// default is read as a JSON value, so it may contain "," and "=". Since a
+// regexp may contain them as well, pattern takes the rest of the tag.
+//
+// It is exported for mocjson-gen, which reads the tags from the source.
+func ParseMocJSONTag(tag string) (map[string]string, error) {
+	opts := make(map[string]string)
+
+	for tag != "" {
+		rest := tag
+
+		var o string
+		o, tag, _ = strings.Cut(tag, ",")
+
+		k, v, hasValue := strings.Cut(o, "=")
+		if hasValue {
+			switch k {
+			case "default":
+				var err error
+				if v, tag, err = cutJSONValue(rest[len(k)+1:]); err != nil {
+					return nil, fmt.Errorf("invalid default: %w", err)
+				}
+
+			case "pattern":
+				v, tag = rest[len(k)+1:], ""
+			}
+		}
+
+		takesValue, ok := mocJSONTagOptions[k]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("unknown option %q", k)
+		case takesValue && !hasValue:
+			return nil, fmt.Errorf("missing value of %s", k)
+		case !takesValue && hasValue:
+			return nil, fmt.Errorf("unexpected value of %s", k)
+		}
+
+		if _, ok := opts[k]; ok {
+			return nil, fmt.Errorf("duplicate option %s", k)
+		}
+		opts[k] = v
+	}
+
+	return opts, nil
+}
+
+// cutJSONValue cuts the JSON value at the beginning of s, and returns the value
+// and the rest after the following ",".
+func cutJSONValue(s string) (string, string, error) {
+	pa := NewParser(strings.NewReader(s))
+
+	v, err := pa.ParseRaw()
+	if err != nil {
+		return "", "", err
+	}
+
+	rest := s[pa.lx.sc.off:]
+	if rest == "" {
+		return string(v), "", nil
+	}
+
+	rest, ok := strings.CutPrefix(rest, ",")
+	if !ok {
+		return "", "", fmt.Errorf("unexpected %q after %s", rest, v)
+	}
+
+	return string(v), rest, nil
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
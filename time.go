@@ -0,0 +1,167 @@
+package mocjson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts for ParseTime and EncodeTime which represent the time as the
+// elapsed time since the Unix epoch. The value is a number, or a string which
+// contains a number.
+const (
+	LayoutUnix      = "unix"
+	LayoutUnixMilli = "unixmilli"
+	LayoutUnixMicro = "unixmicro"
+	LayoutUnixNano  = "unixnano"
+)
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// timeLayouts maps the time=<format> tag option, or the bare <format>, to the
+// layout.
+var timeLayouts = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"datetime":    time.DateTime,
+	"dateonly":    time.DateOnly,
+	"unix":        LayoutUnix,
+	"unixmilli":   LayoutUnixMilli,
+	"unixmicro":   LayoutUnixMicro,
+	"unixnano":    LayoutUnixNano,
+}
+
+var epochUnits = map[string]time.Duration{
+	LayoutUnix:      time.Second,
+	LayoutUnixMilli: time.Millisecond,
+	LayoutUnixMicro: time.Microsecond,
+	LayoutUnixNano:  time.Nanosecond,
+}
+
+func (pa *Parser) ParseTime(layout string) (time.Time, error) {
+	unit, ok := epochUnits[layout]
+	if !ok {
+		s, err := pa.ParseString()
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parse time error: %w", err)
+		}
+
+		return t, nil
+	}
+
+	b, err := pa.parseNumberOrNumericString()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t, err := epochToTime(b, unit)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse time error: %w", err)
+	}
+
+	return t, nil
+}
+
+// ParseDuration parses a string such as "1h30m", or a number of nanoseconds,
+// which is read as the integer types are by Decode.
+func (pa *Parser) ParseDuration() (time.Duration, error) {
+	if pa.lx.NextTokenType() == TokenTypeString {
+		s, err := pa.ParseString()
+		if err != nil {
+			return 0, err
+		}
+
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("parse duration error: %w", err)
+		}
+
+		return d, nil
+	}
+
+	b, ok := pa.lx.ExpectNumberBytes()
+	if !ok {
+		return 0, errors.New("expect duration")
+	}
+
+	n, err := pa.lx.parseInt(b, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse duration error: %w", err)
+	}
+
+	return time.Duration(n), nil
+}
+
+func (pa *Parser) parseNumberOrNumericString() ([]byte, error) {
+	if pa.lx.NextTokenType() != TokenTypeString {
+		b, ok := pa.lx.ExpectNumberBytes()
+		if !ok {
+			return nil, errors.New("expect number")
+		}
+		return b, nil
+	}
+
+	s, err := pa.ParseString()
+	if err != nil {
+		return nil, err
+	}
+
+	lx := NewLexer(strings.NewReader(s))
+	b, ok := lx.ExpectNumberBytes()
+	if !ok || len(b) != len(s) {
+		return nil, fmt.Errorf("expect number in string: %q", s)
+	}
+
+	return b, nil
+}
+
+func epochToTime(b []byte, unit time.Duration) (time.Time, error) {
+	// A huge exponent makes big.Rat slow, so the range is checked first.
+	f, err := strconv.ParseFloat(string(b), 64)
+	if err != nil || math.Abs(f)*float64(unit) > math.MaxInt64*float64(time.Second) {
+		return time.Time{}, fmt.Errorf("out of range: %s", b)
+	}
+
+	ns, ok := new(big.Rat).SetString(string(b))
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid number: %s", b)
+	}
+	ns.Mul(ns, new(big.Rat).SetInt64(int64(unit)))
+
+	billion := big.NewInt(int64(time.Second))
+
+	sec := new(big.Int).Quo(ns.Num(), new(big.Int).Mul(ns.Denom(), billion))
+	nsec := new(big.Rat).Sub(ns, new(big.Rat).SetInt(new(big.Int).Mul(sec, billion)))
+	nsecInt := new(big.Int).Quo(nsec.Num(), nsec.Denom())
+
+	return time.Unix(sec.Int64(), nsecInt.Int64()).UTC(), nil
+}
+
+func (en *Encoder) EncodeTime(t time.Time, layout string) error {
+	unit, ok := epochUnits[layout]
+	if !ok {
+		return en.EncodeString(t.Format(layout))
+	}
+
+	ns := new(big.Int).Mul(big.NewInt(t.Unix()), big.NewInt(int64(time.Second)))
+	ns.Add(ns, big.NewInt(int64(t.Nanosecond())))
+
+	return en.EncodeRat(new(big.Rat).SetFrac(ns, big.NewInt(int64(unit))))
+}
+
+func (en *Encoder) EncodeDuration(d time.Duration) error {
+	return en.EncodeString(d.String())
+}
@@ -0,0 +1,429 @@
+package mocjson
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParser_ParseTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       []byte
+		layout  string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:   "rfc3339",
+			b:      []byte(`"2006-01-02T15:04:05Z"`),
+			layout: time.RFC3339,
+			want:   time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		},
+		{
+			name:   "rfc3339nano",
+			b:      []byte(`"2006-01-02T15:04:05.999999999Z"`),
+			layout: time.RFC3339Nano,
+			want:   time.Date(2006, 1, 2, 15, 4, 5, 999999999, time.UTC),
+		},
+		{
+			name:    "invalid layout",
+			b:       []byte(`"2006/01/02"`),
+			layout:  time.DateOnly,
+			wantErr: true,
+		},
+		{
+			name:    "number with layout",
+			b:       []byte(`1136214245`),
+			layout:  time.RFC3339,
+			wantErr: true,
+		},
+		{
+			name:   "unix",
+			b:      []byte(`1136214245`),
+			layout: LayoutUnix,
+			want:   time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		},
+		{
+			name:   "unix frac",
+			b:      []byte(`1136214245.123456789`),
+			layout: LayoutUnix,
+			want:   time.Date(2006, 1, 2, 15, 4, 5, 123456789, time.UTC),
+		},
+		{
+			name:   "unix negative frac",
+			b:      []byte(`-1.5`),
+			layout: LayoutUnix,
+			want:   time.Date(1969, 12, 31, 23, 59, 58, 500000000, time.UTC),
+		},
+		{
+			name:   "unix exp",
+			b:      []byte(`1.136214245e9`),
+			layout: LayoutUnix,
+			want:   time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		},
+		{
+			name:   "unix string",
+			b:      []byte(`"1136214245"`),
+			layout: LayoutUnix,
+			want:   time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		},
+		{
+			name:    "unix invalid string",
+			b:       []byte(`"1136214245s"`),
+			layout:  LayoutUnix,
+			wantErr: true,
+		},
+		{
+			name:    "unix out of range",
+			b:       []byte(`1e1000000000`),
+			layout:  LayoutUnix,
+			wantErr: true,
+		},
+		{
+			name:   "unixmilli",
+			b:      []byte(`1136214245123`),
+			layout: LayoutUnixMilli,
+			want:   time.Date(2006, 1, 2, 15, 4, 5, 123000000, time.UTC),
+		},
+		{
+			name:   "unixmicro",
+			b:      []byte(`1136214245123456`),
+			layout: LayoutUnixMicro,
+			want:   time.Date(2006, 1, 2, 15, 4, 5, 123456000, time.UTC),
+		},
+		{
+			name:   "unixnano",
+			b:      []byte(`"1136214245123456789"`),
+			layout: LayoutUnixNano,
+			want:   time.Date(2006, 1, 2, 15, 4, 5, 123456789, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bytes.NewReader(tt.b)
+			pa := NewParser(r)
+
+			got, err := pa.ParseTime(tt.layout)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkParser_ParseTime(b *testing.B) {
+	layouts := map[string][]byte{
+		time.RFC3339Nano: []byte(`"2006-01-02T15:04:05.999999999Z"`),
+		LayoutUnixMilli:  []byte(`1136214245123`),
+	}
+
+	for layout, bs := range layouts {
+		b.Run(layout, func(b *testing.B) {
+			r := bytes.NewReader(bs)
+			pa := NewParser(r)
+
+			b.ResetTimer()
+			for b.Loop() {
+				r.Reset(bs)
+				pa.reset()
+				pa.ParseTime(layout)
+			}
+		})
+	}
+}
+
+func TestParser_ParseDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		b       []byte
+		json5   bool
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name: "string",
+			b:    []byte(`"1h30m"`),
+			want: 90 * time.Minute,
+		},
+		{
+			name: "number",
+			b:    []byte(`1500`),
+			want: 1500 * time.Nanosecond,
+		},
+		{
+			name:    "invalid string",
+			b:       []byte(`"1 hour"`),
+			wantErr: true,
+		},
+		{
+			name:    "frac",
+			b:       []byte(`1.5`),
+			wantErr: true,
+		},
+		{
+			name:    "null",
+			b:       []byte(`null`),
+			wantErr: true,
+		},
+		{
+			name: "number with exp",
+			b:    []byte(`1.5e3`),
+			want: 1500 * time.Nanosecond,
+		},
+		{
+			name:  "json5 hex",
+			b:     []byte(`0x5DC`),
+			json5: true,
+			want:  1500 * time.Nanosecond,
+		},
+		{
+			name:  "json5 plus sign",
+			b:     []byte(`+1500`),
+			json5: true,
+			want:  1500 * time.Nanosecond,
+		},
+		{
+			name:    "json5 infinity",
+			b:       []byte(`Infinity`),
+			json5:   true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bytes.NewReader(tt.b)
+			pa := NewParserWithOptions(r, ParserOptions{JSON5: tt.json5})
+
+			got, err := pa.ParseDuration()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoder_EncodeTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		t      time.Time
+		layout string
+		want   string
+	}{
+		{
+			name:   "rfc3339nano",
+			t:      time.Date(2006, 1, 2, 15, 4, 5, 123000000, time.UTC),
+			layout: time.RFC3339Nano,
+			want:   `"2006-01-02T15:04:05.123Z"`,
+		},
+		{
+			name:   "unix",
+			t:      time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+			layout: LayoutUnix,
+			want:   `1136214245`,
+		},
+		{
+			name:   "unix frac",
+			t:      time.Date(2006, 1, 2, 15, 4, 5, 123456789, time.UTC),
+			layout: LayoutUnix,
+			want:   `1136214245.123456789`,
+		},
+		{
+			name:   "unix negative frac",
+			t:      time.Date(1969, 12, 31, 23, 59, 58, 500000000, time.UTC),
+			layout: LayoutUnix,
+			want:   `-1.5`,
+		},
+		{
+			name:   "unixmilli",
+			t:      time.Date(2006, 1, 2, 15, 4, 5, 123000000, time.UTC),
+			layout: LayoutUnixMilli,
+			want:   `1136214245123`,
+		},
+		{
+			name:   "unixnano",
+			t:      time.Date(2006, 1, 2, 15, 4, 5, 123456789, time.UTC),
+			layout: LayoutUnixNano,
+			want:   `1136214245123456789`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			en := NewEncoder(&buf)
+
+			if err := en.EncodeTime(tt.t, tt.layout); err != nil {
+				t.Fatal(err)
+			}
+			if err := en.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+type timeTestObject struct {
+	Created  time.Time      `json:"created,time=rfc3339nano"`
+	Updated  *time.Time     `json:"updated,time=unixmilli"`
+	Expires  time.Time      `json:"expires,omitempty,unix"`
+	Timeout  time.Duration  `json:"timeout,duration"`
+	Interval *time.Duration `json:"interval,omitempty,duration"`
+	Elapsed  time.Duration  `json:"elapsed"`
+}
+
+func TestTimeTagOptions(t *testing.T) {
+	t.Parallel()
+
+	updated := time.Date(2006, 1, 2, 15, 4, 5, 123000000, time.UTC)
+
+	tests := []struct {
+		name    string
+		b       []byte
+		want    timeTestObject
+		wantEnc string
+		wantErr bool
+	}{
+		{
+			name: "all",
+			b: []byte(
+				`{"created":"2006-01-02T15:04:05.999999999Z","updated":1136214245123,"expires":"1136214245","timeout":"1m30s","interval":1000,"elapsed":1000}`,
+			),
+			want: timeTestObject{
+				Created:  time.Date(2006, 1, 2, 15, 4, 5, 999999999, time.UTC),
+				Updated:  &updated,
+				Expires:  time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+				Timeout:  90 * time.Second,
+				Interval: func() *time.Duration { d := time.Microsecond; return &d }(),
+				Elapsed:  time.Microsecond,
+			},
+			wantEnc: `{"created":"2006-01-02T15:04:05.999999999Z","updated":1136214245123,"expires":1136214245,"timeout":"1m30s","interval":"1µs","elapsed":1000}`,
+		},
+		{
+			name: "null and missing",
+			b: []byte(
+				`{"created":"2006-01-02T15:04:05Z","updated":null,"timeout":0,"elapsed":0}`,
+			),
+			want: timeTestObject{
+				Created: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+			},
+			wantEnc: `{"created":"2006-01-02T15:04:05Z","updated":null,"expires":-62135596800,"timeout":"0s","elapsed":0}`,
+		},
+		{
+			name:    "invalid time",
+			b:       []byte(`{"created":1136214245,"updated":null,"timeout":0,"elapsed":0}`),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bytes.NewReader(tt.b)
+			pa := NewParser(r)
+
+			var got timeTestObject
+			err := pa.Decode(&got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("gotErr %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			var buf bytes.Buffer
+			en := NewEncoder(&buf)
+			if err := en.Encode(got); err != nil {
+				t.Fatal(err)
+			}
+			if gotEnc := buf.String(); gotEnc != tt.wantEnc {
+				t.Errorf("got %s, want %s", gotEnc, tt.wantEnc)
+			}
+		})
+	}
+}
+
+func TestTimeTagOptions_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{
+			name: "unknown format",
+			v: &struct {
+				T time.Time `json:"t,time=iso"`
+			}{},
+		},
+		{
+			name: "time option on string",
+			v: &struct {
+				T string `json:"t,time=unix"`
+			}{},
+		},
+		{
+			name: "bare format on string",
+			v: &struct {
+				T string `json:"t,unixmilli"`
+			}{},
+		},
+		{
+			name: "multiple formats",
+			v: &struct {
+				T time.Time `json:"t,time=unix,unixmilli"`
+			}{},
+		},
+		{
+			name: "duration option on int64",
+			v: &struct {
+				D int64 `json:"d,duration"`
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pa := NewParser(bytes.NewReader([]byte(`{"t":"0","d":"0"}`)))
+			if err := pa.Decode(tt.v); err == nil {
+				t.Errorf("got nil, want error")
+			}
+
+			var buf bytes.Buffer
+			en := NewEncoder(&buf)
+			if err := en.Encode(tt.v); err == nil {
+				t.Errorf("got nil, want error")
+			}
+		})
+	}
+}
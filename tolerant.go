@@ -0,0 +1,328 @@
+package mocjson
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+)
+
+// SyntaxError is a problem found by ParseTolerant.
+type SyntaxError struct {
+	// Offset is the byte offset of the problem in the input.
+	Offset int64
+	// Line and Column are 1-based. Column counts bytes.
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// SyntaxErrorList is the list of the problems in the order of the offsets.
+type SyntaxErrorList []*SyntaxError
+
+func (l SyntaxErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// ParseTolerant parses a value from r like Parse, but does not stop at the
+// first problem. It records the problem, resynchronises at the next ',', ']'
+// or '}', and keeps going, so it returns the partial value along with a
+// SyntaxErrorList of all the problems.
+//
+// In the partial value, a value which cannot be parsed is nil, the first of
+// duplicate keys wins, and a missing separator or a trailing comma is
+// tolerated. If reading r fails, the returned error wraps the error as well.
+func ParseTolerant(r io.Reader) (any, error) {
+	lr := &lineReader{r: r}
+	tp := tolerantParser{pa: NewParser(lr), lr: lr}
+
+	v := tp.parseValue()
+
+	if !tp.pa.lx.ExpectEOF() && tp.pa.lx.sc.BufferedLen() > 0 {
+		tp.addError("expect EOF")
+	}
+
+	slices.SortStableFunc(tp.errs, func(a, b *SyntaxError) int {
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+
+	if err := tp.pa.lx.sc.Err(); err != nil && err != io.EOF {
+		err = fmt.Errorf("read error: %w", err)
+		if len(tp.errs) > 0 {
+			err = errors.Join(tp.errs, err)
+		}
+		return v, err
+	}
+
+	if len(tp.errs) > 0 {
+		return v, tp.errs
+	}
+
+	return v, nil
+}
+
+// lineReader records the offsets of the line starts of the bytes read.
+type lineReader struct {
+	r io.Reader
+	n int64
+
+	// lines holds the offsets of the line starts except for the first line.
+	lines []int64
+}
+
+func (lr *lineReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+
+	for i, b := range p[:n] {
+		if b == '\n' {
+			lr.lines = append(lr.lines, lr.n+int64(i)+1)
+		}
+	}
+	lr.n += int64(n)
+
+	return n, err
+}
+
+// position returns the 1-based line and column of off.
+func (lr *lineReader) position(off int64) (int, int) {
+	i := sort.Search(len(lr.lines), func(i int) bool { return lr.lines[i] > off })
+	if i == 0 {
+		return 1, int(off) + 1
+	}
+
+	return i + 1, int(off-lr.lines[i-1]) + 1
+}
+
+type tolerantParser struct {
+	pa   Parser
+	lr   *lineReader
+	errs SyntaxErrorList
+}
+
+// addError records a problem at the current offset.
+func (tp *tolerantParser) addError(msg string) {
+	tp.addErrorAt(tp.pa.lx.sc.off, msg)
+}
+
+func (tp *tolerantParser) addErrorAt(off int64, msg string) {
+	line, col := tp.lr.position(off)
+	tp.errs = append(tp.errs, &SyntaxError{Offset: off, Line: line, Column: col, Msg: msg})
+}
+
+// resync skips to the next ',', ']' or '}', or to EOF.
+func (tp *tolerantParser) resync() {
+	sc := &tp.pa.lx.sc
+
+	for sc.Load() {
+		if i := bytes.IndexAny(sc.buf, ",]}"); i >= 0 {
+			sc.Skip(i)
+			return
+		}
+		sc.Skip(len(sc.buf))
+	}
+}
+
+func (tp *tolerantParser) parseValue() any {
+	lx := &tp.pa.lx
+
+	var msg string
+
+	switch lx.NextTokenType() {
+	case TokenTypeBeginArray:
+		return tp.parseArray()
+
+	case TokenTypeBeginObject:
+		return tp.parseObject()
+
+	case TokenTypeEOF:
+		tp.addError("unexpected EOF")
+		return nil
+
+	case TokenTypeEndArray, TokenTypeEndObject, TokenTypeValueSeparator:
+		// The caller handles the token.
+		tp.addError("expect value")
+		return nil
+
+	case TokenTypeNull:
+		msg = "invalid null"
+	case TokenTypeBool:
+		msg = "invalid bool"
+	case TokenTypeNumber:
+		msg = "invalid number"
+	case TokenTypeString:
+		msg = "invalid string"
+	default:
+		msg = "invalid token"
+	}
+
+	off := lx.sc.off
+
+	v, err := tp.pa.ParseValue()
+	if err != nil {
+		tp.addErrorAt(off, msg)
+		tp.resync()
+		return nil
+	}
+
+	return v
+}
+
+func (tp *tolerantParser) parseArray() []any {
+	lx := &tp.pa.lx
+
+	start := lx.sc.off
+	lx.ExpectBeginArray()
+
+	ret := make([]any, 0)
+
+	if lx.NextTokenType() == TokenTypeEndArray {
+		lx.sc.Skip(1)
+		return ret
+	}
+
+	for {
+		ret = append(ret, tp.parseValue())
+
+		if !tp.separator(TokenTypeEndArray, start, "array") {
+			return ret
+		}
+	}
+}
+
+func (tp *tolerantParser) parseObject() map[string]any {
+	lx := &tp.pa.lx
+
+	start := lx.sc.off
+	lx.ExpectBeginObject()
+
+	ret := make(map[string]any)
+
+	if lx.NextTokenType() == TokenTypeEndObject {
+		lx.sc.Skip(1)
+		return ret
+	}
+
+	for {
+		tp.parseMember(ret)
+
+		if !tp.separator(TokenTypeEndObject, start, "object") {
+			return ret
+		}
+	}
+}
+
+func (tp *tolerantParser) parseMember(ret map[string]any) {
+	lx := &tp.pa.lx
+
+	var (
+		k   string
+		dup bool
+	)
+
+	switch lx.NextTokenType() {
+	case TokenTypeString:
+		off := lx.sc.off
+
+		var ok bool
+		k, ok = lx.ExpectString()
+		if !ok {
+			tp.addErrorAt(off, "invalid string")
+			tp.resync()
+			return
+		}
+
+		if _, dup = ret[k]; dup {
+			tp.addErrorAt(off, fmt.Sprintf("duplicate key %q", k))
+		}
+
+	case TokenTypeValueSeparator, TokenTypeEndArray, TokenTypeEndObject, TokenTypeEOF:
+		tp.addError("expect string")
+		return
+
+	default:
+		tp.addError("expect string")
+		tp.resync()
+		return
+	}
+
+	var v any
+
+	if !lx.ExpectNameSeparator() {
+		tp.addError("expect name separator")
+
+		switch lx.NextTokenType() {
+		case TokenTypeValueSeparator, TokenTypeEndArray, TokenTypeEndObject, TokenTypeEOF:
+		case TokenTypeInvalid:
+			tp.resync()
+		default:
+			// The separator is missing, so the value follows.
+			v = tp.parseValue()
+		}
+	} else {
+		v = tp.parseValue()
+	}
+
+	// The first one of the duplicate keys wins.
+	if !dup {
+		ret[k] = v
+	}
+}
+
+// separator reads the token after an element of the array or the object which
+// starts at start and ends with end. It reports whether another element
+// follows.
+func (tp *tolerantParser) separator(end TokenType, start int64, name string) bool {
+	lx := &tp.pa.lx
+
+	for {
+		switch lx.NextTokenType() {
+		case end:
+			lx.sc.Skip(1)
+			return false
+
+		case TokenTypeValueSeparator:
+			off := lx.sc.off
+			lx.sc.Skip(1)
+
+			if lx.NextTokenType() == end {
+				tp.addErrorAt(off, "unexpected trailing comma")
+				lx.sc.Skip(1)
+				return false
+			}
+			return true
+
+		case TokenTypeEOF:
+			tp.addErrorAt(start, "unterminated "+name)
+			return false
+
+		case TokenTypeEndArray, TokenTypeEndObject:
+			// The bracket probably closes the enclosing one, so it is left to
+			// the caller.
+			tp.addError("expect value separator or end " + name)
+			return false
+
+		case TokenTypeNameSeparator, TokenTypeInvalid:
+			tp.addError("expect value separator or end " + name)
+			tp.resync()
+
+		default:
+			// The separator is missing, so the next element follows.
+			tp.addError("expect value separator or end " + name)
+			return true
+		}
+	}
+}
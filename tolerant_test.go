@@ -0,0 +1,168 @@
+package mocjson
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestParseTolerant(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		b        string
+		want     any
+		wantErrs []string
+	}{
+		{
+			name: "valid",
+			b:    ` {"a": [1, "x", true, null], "b": {}} `,
+			want: map[string]any{
+				"a": []any{1.0, "x", true, nil},
+				"b": map[string]any{},
+			},
+		},
+		{
+			name:     "invalid values",
+			b:        "{\n  \"a\": tru,\n  \"b\": 1,\n  \"c\": -x\n}",
+			want:     map[string]any{"a": nil, "b": 1.0, "c": nil},
+			wantErrs: []string{"2:8: invalid bool", "4:8: invalid number"},
+		},
+		{
+			name: "trailing commas",
+			b:    `{"a": [1, 2,], "b": 3,}`,
+			want: map[string]any{"a": []any{1.0, 2.0}, "b": 3.0},
+			wantErrs: []string{
+				"1:12: unexpected trailing comma",
+				"1:22: unexpected trailing comma",
+			},
+		},
+		{
+			name: "missing separators",
+			b:    `{"a" 1 "b": [1 2]}`,
+			want: map[string]any{"a": 1.0, "b": []any{1.0, 2.0}},
+			wantErrs: []string{
+				"1:6: expect name separator",
+				"1:8: expect value separator or end object",
+				"1:16: expect value separator or end array",
+			},
+		},
+		{
+			name: "missing values",
+			b:    `[1,,2,{"a":,"b"}]`,
+			want: []any{1.0, nil, 2.0, map[string]any{"a": nil, "b": nil}},
+			wantErrs: []string{
+				"1:4: expect value",
+				"1:12: expect value",
+				"1:16: expect name separator",
+			},
+		},
+		{
+			name:     "invalid keys",
+			b:        `{1: 2, "a": 3, x y: 4}`,
+			want:     map[string]any{"a": 3.0},
+			wantErrs: []string{"1:2: expect string", "1:16: expect string"},
+		},
+		{
+			name:     "duplicate key",
+			b:        `{"a": 1, "a": 2}`,
+			want:     map[string]any{"a": 1.0},
+			wantErrs: []string{`1:10: duplicate key "a"`},
+		},
+		{
+			name: "garbage after value",
+			b:    `[1 x, 2 : 3]`,
+			want: []any{1.0, 2.0},
+			wantErrs: []string{
+				"1:4: expect value separator or end array",
+				"1:9: expect value separator or end array",
+			},
+		},
+		{
+			name:     "mismatched bracket",
+			b:        `{"a": [1, 2}`,
+			want:     map[string]any{"a": []any{1.0, 2.0}},
+			wantErrs: []string{"1:12: expect value separator or end array"},
+		},
+		{
+			name: "unterminated",
+			b:    "{\"a\": [1,\n  {\"b\": \"c",
+			want: map[string]any{"a": []any{1.0, map[string]any{"b": nil}}},
+			wantErrs: []string{
+				"1:1: unterminated object",
+				"1:7: unterminated array",
+				"2:3: unterminated object",
+				"2:9: invalid string",
+			},
+		},
+		{
+			name:     "empty",
+			b:        " \n ",
+			want:     nil,
+			wantErrs: []string{"2:2: unexpected EOF"},
+		},
+		{
+			name:     "trailing garbage",
+			b:        `[1] 2`,
+			want:     []any{1.0},
+			wantErrs: []string{"1:5: expect EOF"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseTolerant(strings.NewReader(tt.b))
+
+			var gotErrs []string
+			if err != nil {
+				var list SyntaxErrorList
+				if !errors.As(err, &list) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				for _, e := range list {
+					gotErrs = append(gotErrs, e.Error())
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("got errors %q, want %q", gotErrs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestParseTolerant_ReadError(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read error")
+	_, err := ParseTolerant(iotest.DataErrReader(iotest.ErrReader(errRead)))
+
+	if !errors.Is(err, errRead) {
+		t.Errorf("got %v, want %v", err, errRead)
+	}
+	var list SyntaxErrorList
+	if !errors.As(err, &list) || len(list) != 1 {
+		t.Errorf("got %v, want a problem at EOF", err)
+	}
+}
+
+func BenchmarkParseTolerant(b *testing.B) {
+	s := "[" + strings.Repeat(`{"id": "a", "n": 1, "ok": tru, "tags": ["x", "y",]},`, 999) +
+		`{"id": "a"}]`
+	r := strings.NewReader(s)
+
+	for b.Loop() {
+		r.Reset(s)
+		if _, err := ParseTolerant(r); err == nil {
+			b.Fatal("want errors")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package mocjson
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+)
+
+type union struct {
+	key      string
+	variants map[string]reflect.Type
+	// values holds the discriminator value which Encoder writes for each
+	// variant.
+	values map[reflect.Type]string
+}
+
+var unionRegistry struct {
+	mu     sync.RWMutex
+	unions map[reflect.Type]*union
+}
+
+// RegisterVariant registers T as the concrete type which Decode stores into
+// the interface type I when the object's discriminator key has the value.
+// All variants of I must share the same key. The key does not have to be the
+// first one in the object.
+//
+// T may be registered with more than one value. Decode accepts all of them,
+// and Encoder writes the first one.
+//
+// RegisterVariant panics if I is not an interface type, T does not implement
+// I, or the registration conflicts with earlier ones.
+func RegisterVariant[I, T any](key, value string) {
+	it := reflect.TypeFor[I]()
+	t := reflect.TypeFor[T]()
+
+	if it.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("mocjson: %v is not an interface type", it))
+	}
+	if !t.Implements(it) {
+		panic(fmt.Sprintf("mocjson: %v does not implement %v", t, it))
+	}
+
+	unionRegistry.mu.Lock()
+	defer unionRegistry.mu.Unlock()
+
+	if unionRegistry.unions == nil {
+		unionRegistry.unions = make(map[reflect.Type]*union)
+	}
+
+	prev, ok := unionRegistry.unions[it]
+	if !ok {
+		prev = &union{key: key}
+	}
+
+	if prev.key != key {
+		panic(fmt.Sprintf("mocjson: %v is already registered with key %q", it, prev.key))
+	}
+	if pt, ok := prev.variants[value]; ok && pt != t {
+		panic(fmt.Sprintf("mocjson: %s %q of %v is already registered as %v", key, value, it, pt))
+	}
+
+	// The variants are copied on write since lookups do not hold the lock.
+	u := &union{key: key, variants: maps.Clone(prev.variants), values: maps.Clone(prev.values)}
+	if u.variants == nil {
+		u.variants = make(map[string]reflect.Type)
+		u.values = make(map[reflect.Type]string)
+	}
+	u.variants[value] = t
+	if _, ok := u.values[t]; !ok {
+		u.values[t] = value
+	}
+	unionRegistry.unions[it] = u
+}
+
+func lookupUnion(it reflect.Type) (*union, bool) {
+	unionRegistry.mu.RLock()
+	defer unionRegistry.mu.RUnlock()
+
+	u, ok := unionRegistry.unions[it]
+	return u, ok
+}
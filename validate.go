@@ -0,0 +1,264 @@
+package mocjson
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError is returned when a decoded value violates a validation
+// option of the mocjson tag: min, max, minLength, maxLength, pattern, enum or
+// nonempty.
+type ValidationError struct {
+	// Path is the JSON path of the value relative to the decoded value, e.g.
+	// ".items[0].name".
+	Path string
+	// Rule is the violated option.
+	Rule string
+	Msg  string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("$%s: %s: %s", e.Path, e.Rule, e.Msg)
+}
+
+// PrependPath prepends seg to the path of the ValidationError in err, e.g. the
+// one returned by KeyPath or IndexPath. It is exported for the code generated
+// by mocjson-gen, which reports the paths as Decode does.
+func PrependPath(err error, seg string) error {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		ve.Path = seg + ve.Path
+	}
+
+	return err
+}
+
+// IndexPath returns the path segment of the index i, e.g. "[0]".
+func IndexPath(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+// KeyPath returns the path segment of the key k: ".key" for the
+// identifier-like keys, and `["key"]` for the others.
+func KeyPath(k string) string {
+	if k == "" {
+		return `[""]`
+	}
+
+	for i, c := range k {
+		isAlpha := c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+		isDigit := '0' <= c && c <= '9'
+		if !isAlpha && (i == 0 || !isDigit) {
+			return "[" + strconv.Quote(k) + "]"
+		}
+	}
+
+	return "." + k
+}
+
+type rules struct {
+	min, max             float64
+	hasMin, hasMax       bool
+	minLength, maxLength int
+	hasMinLength         bool
+	hasMaxLength         bool
+	pattern              *regexp.Regexp
+	enum                 []string
+	nonempty             bool
+}
+
+// newRules parses the validation options. It returns nil if there are no
+// validation options.
+func newRules(sf reflect.StructField, opts map[string]string) (*rules, error) {
+	var r rules
+	found := false
+
+	k := indirectType(sf.Type).Kind()
+	isString := k == reflect.String
+	isInteger := reflect.Int <= k && k <= reflect.Uintptr
+	isNumber := reflect.Int <= k && k <= reflect.Float64
+	hasLength := isString || k == reflect.Slice || k == reflect.Array || k == reflect.Map
+
+	// lookup returns the value of the option key which is allowed if ok.
+	lookup := func(key string, ok bool) (string, bool, error) {
+		v, has := opts[key]
+		switch {
+		case !has:
+			return "", false, nil
+		case !ok:
+			return "", false, fmt.Errorf("field %s: %s option on %v", sf.Name, key, sf.Type)
+		}
+		found = true
+		return v, true, nil
+	}
+
+	for _, o := range []struct {
+		key string
+		v   *float64
+		has *bool
+	}{
+		{"min", &r.min, &r.hasMin},
+		{"max", &r.max, &r.hasMax},
+	} {
+		v, ok, err := lookup(o.key, isNumber)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if *o.v, err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("field %s: invalid %s: %w", sf.Name, o.key, err)
+		}
+		*o.has = true
+	}
+
+	for _, o := range []struct {
+		key string
+		v   *int
+		has *bool
+	}{
+		{"minLength", &r.minLength, &r.hasMinLength},
+		{"maxLength", &r.maxLength, &r.hasMaxLength},
+	} {
+		v, ok, err := lookup(o.key, hasLength)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if *o.v, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("field %s: invalid %s: %w", sf.Name, o.key, err)
+		}
+		*o.has = true
+	}
+
+	if v, ok := opts["pattern"]; ok {
+		if !isString {
+			return nil, fmt.Errorf("field %s: pattern option on %v", sf.Name, sf.Type)
+		}
+
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: invalid pattern: %w", sf.Name, err)
+		}
+		r.pattern = re
+		found = true
+	}
+
+	if v, ok := opts["enum"]; ok {
+		if !isString && !isInteger {
+			return nil, fmt.Errorf("field %s: enum option on %v", sf.Name, sf.Type)
+		}
+		r.enum = strings.Split(v, "|")
+		found = true
+	}
+
+	if _, ok := opts["nonempty"]; ok {
+		if !hasLength && sf.Type.Kind() != reflect.Pointer {
+			return nil, fmt.Errorf("field %s: nonempty option on %v", sf.Name, sf.Type)
+		}
+		r.nonempty = true
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return &r, nil
+}
+
+func (r *rules) check(rv reflect.Value) error {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			if r.nonempty {
+				return &ValidationError{Rule: "nonempty", Msg: "null"}
+			}
+			return nil
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if err := r.checkNumber(float64(rv.Int())); err != nil {
+			return err
+		}
+		return r.checkEnum(strconv.FormatInt(rv.Int(), 10))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		if err := r.checkNumber(float64(rv.Uint())); err != nil {
+			return err
+		}
+		return r.checkEnum(strconv.FormatUint(rv.Uint(), 10))
+
+	case reflect.Float32, reflect.Float64:
+		return r.checkNumber(rv.Float())
+
+	case reflect.String:
+		s := rv.String()
+		if err := r.checkLength(utf8.RuneCountInString(s)); err != nil {
+			return err
+		}
+		if r.pattern != nil && !r.pattern.MatchString(s) {
+			return &ValidationError{
+				Rule: "pattern",
+				Msg:  fmt.Sprintf("%q does not match %s", s, r.pattern),
+			}
+		}
+		return r.checkEnum(s)
+
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return r.checkLength(rv.Len())
+	}
+
+	return nil
+}
+
+func (r *rules) checkNumber(v float64) error {
+	if r.hasMin && v < r.min {
+		return &ValidationError{Rule: "min", Msg: fmt.Sprintf("%v is less than %v", v, r.min)}
+	}
+	if r.hasMax && v > r.max {
+		return &ValidationError{Rule: "max", Msg: fmt.Sprintf("%v is greater than %v", v, r.max)}
+	}
+
+	return nil
+}
+
+func (r *rules) checkLength(n int) error {
+	if r.nonempty && n == 0 {
+		return &ValidationError{Rule: "nonempty", Msg: "empty"}
+	}
+	if r.hasMinLength && n < r.minLength {
+		return &ValidationError{
+			Rule: "minLength",
+			Msg:  fmt.Sprintf("length %d is less than %d", n, r.minLength),
+		}
+	}
+	if r.hasMaxLength && n > r.maxLength {
+		return &ValidationError{
+			Rule: "maxLength",
+			Msg:  fmt.Sprintf("length %d is greater than %d", n, r.maxLength),
+		}
+	}
+
+	return nil
+}
+
+func (r *rules) checkEnum(s string) error {
+	if r.enum != nil && !slices.Contains(r.enum, s) {
+		return &ValidationError{Rule: "enum", Msg: fmt.Sprintf("%q is not one of %q", s, r.enum)}
+	}
+
+	return nil
+}